@@ -0,0 +1,93 @@
+// Package analyzer provides a go/analysis Analyzer that reports functions
+// with too many prime paths.
+package analyzer
+
+import (
+	"errors"
+	"go/ast"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/ctrlflow"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/cfg"
+)
+
+var (
+	maxPrimePaths int
+	maxPaths      int
+)
+
+// Analyzer reports functions whose number of prime paths exceeds the
+// -max-prime-paths flag, or cannot be counted within -max-paths candidate
+// paths. CFGs come from the ctrlflow pass, so calls that
+// type checking proves never return, such as os.Exit, end their block.
+var Analyzer = &analysis.Analyzer{
+	Name:     "primepath",
+	Doc:      "report functions with more prime paths than -max-prime-paths",
+	Run:      run,
+	Requires: []*analysis.Analyzer{inspect.Analyzer, ctrlflow.Analyzer},
+}
+
+func init() {
+	Analyzer.Flags.IntVar(&maxPrimePaths, "max-prime-paths", 50, "report functions with more prime paths than this")
+	Analyzer.Flags.IntVar(&maxPaths, "max-paths", 100000, "stop enumerating candidate prime paths of a function after this many")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
+
+	nodeFilter := []ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}
+	var err error
+	insp.Preorder(nodeFilter, func(n ast.Node) {
+		if err != nil {
+			return
+		}
+
+		var g *cfg.CFG
+		var name string
+		var pos ast.Node
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Body == nil {
+				return
+			}
+			g, name, pos = cfgs.FuncDecl(n), primepath.FuncName(n), n.Name
+		case *ast.FuncLit:
+			g, name, pos = cfgs.FuncLit(n), "function literal", n.Type
+		}
+		if g == nil {
+			return
+		}
+
+		count, candidates, truncated, cerr := countPrimePaths(g)
+		if cerr != nil {
+			err = cerr
+			return
+		}
+		// Candidates left out can contain the prime paths found so far,
+		// so a truncated count bounds the real one neither way.
+		if truncated {
+			pass.Reportf(pos.Pos(), "%s has a prime path count incomplete after %d candidates (max %d prime paths)", name, candidates, maxPrimePaths)
+		} else if count > maxPrimePaths {
+			pass.Reportf(pos.Pos(), "%s has %d prime paths (max %d)", name, count, maxPrimePaths)
+		}
+	})
+	return nil, err
+}
+
+func countPrimePaths(g *cfg.CFG) (count, candidates int, truncated bool, err error) {
+	graph, _, err := primepath.BuildGraph(g)
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	paths, err := primepath.FindCandidatePaths(graph, primepath.Limits{MaxPaths: maxPaths})
+	truncated = errors.Is(err, primepath.ErrTruncated)
+	if err != nil && !truncated {
+		return 0, 0, false, err
+	}
+	return len(primepath.FilterPrimePaths(paths)), len(paths), truncated, nil
+}
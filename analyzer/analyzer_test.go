@@ -0,0 +1,27 @@
+package analyzer
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// setFlag sets the analyzer flag name to value for the rest of the test.
+func setFlag(t *testing.T, name, value string) {
+	t.Helper()
+	old := Analyzer.Flags.Lookup(name).Value.String()
+	if err := Analyzer.Flags.Set(name, value); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Analyzer.Flags.Set(name, old) })
+}
+
+func TestMaxPrimePaths(t *testing.T) {
+	setFlag(t, "max-prime-paths", "4")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "threshold")
+}
+
+func TestTruncated(t *testing.T) {
+	setFlag(t, "max-paths", "5")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "truncated")
+}
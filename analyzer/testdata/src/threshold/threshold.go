@@ -0,0 +1,28 @@
+package threshold
+
+func few(x int) int {
+	if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func many(xs []int) (n int) { // want `many has 9 prime paths \(max 4\)`
+	for _, x := range xs {
+		for x > 0 {
+			x--
+			n++
+		}
+	}
+	return n
+}
+
+var literal = func(xs []int) (n int) { // want `function literal has 9 prime paths \(max 4\)`
+	for _, x := range xs {
+		for x > 0 {
+			x--
+			n++
+		}
+	}
+	return n
+}
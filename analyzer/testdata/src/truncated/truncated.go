@@ -0,0 +1,11 @@
+package truncated
+
+func nested(xs []int) (n int) { // want `nested has a prime path count incomplete after 5 candidates \(max 50 prime paths\)`
+	for _, x := range xs {
+		for x > 0 {
+			x--
+			n++
+		}
+	}
+	return n
+}
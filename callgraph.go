@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+	"golang.org/x/tools/go/packages"
+)
+
+// processCallGraph prints the prime paths of the call graph of each
+// package among args: the packages matching them with -packages, or else
+// the files they name grouped by directory and package clause.
+func (r *runner) processCallGraph(w io.Writer, args []string) error {
+	if r.opts.packages {
+		config := &packages.Config{
+			Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+				packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+			Tests: r.opts.tests,
+		}
+		if len(r.opts.tags) > 0 {
+			config.BuildFlags = []string{"-tags=" + strings.Join(r.opts.tags, ",")}
+		}
+		pkgs, err := packages.Load(config, args...)
+		if err != nil {
+			return err
+		}
+		failed := false
+		for _, pkg := range pkgs {
+			for _, perr := range pkg.Errors {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", perr)
+				failed = true
+			}
+			if isTestMain(pkg) {
+				continue
+			}
+			var cg *primepath.CallGraph
+			if r.opts.callInclude != nil || r.opts.callExclude != nil {
+				cg = primepath.BuildCallGraphFunc(pkg.Syntax, pkg.TypesInfo, r.followCall)
+			} else {
+				cg = primepath.BuildCallGraph(pkg.Syntax, pkg.TypesInfo)
+			}
+			r.printCallGraph(w, pkg.ID, cg)
+		}
+		if failed {
+			return fmt.Errorf("errors while loading packages")
+		}
+		return nil
+	}
+
+	type group struct {
+		name  string
+		files []*ast.File
+	}
+	var groups []*group
+	byName := make(map[string]*group)
+	failed := false
+	for _, arg := range args {
+		files, generated, err := collectFiles(arg, r.opts.tests, r.opts.generated, buildContext(r.opts.tags))
+		r.generated += generated
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+		}
+		for _, filename := range files {
+			var src any
+			if filename == "-" {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("stdin: %w", err)
+				}
+				filename, src = "stdin", data
+			}
+			file, err := parser.ParseFile(token.NewFileSet(), filename, src, 0)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
+				continue
+			}
+			name := filepath.Dir(filename) + ":" + file.Name.Name
+			if byName[name] == nil {
+				byName[name] = &group{name: name}
+				groups = append(groups, byName[name])
+			}
+			byName[name].files = append(byName[name].files, file)
+		}
+	}
+	for _, g := range groups {
+		r.printCallGraph(w, g.name, primepath.BuildCallGraph(g.files, nil))
+	}
+	if failed {
+		return fmt.Errorf("errors while reading files")
+	}
+	return nil
+}
+
+func (r *runner) printCallGraph(w io.Writer, name string, cg *primepath.CallGraph) {
+	fmt.Fprintf(w, "=== Call graph: %s ===\n", name)
+
+	fmt.Fprintln(w, "\nFunctions:")
+	for i, fn := range cg.Names {
+		fmt.Fprintf(w, "  %d: %s\n", i, fn)
+	}
+	if r.opts.verbose >= 1 {
+		printGraphInfo(w, cg.Graph, &palette{})
+	}
+
+	paths, err := primepath.FindCandidatePaths(cg.Graph, r.opts.analysis.Limits)
+	fmt.Fprintln(w, "\nPrime Paths:")
+	if err != nil {
+		fmt.Fprintln(w, "  (incomplete: path enumeration was truncated)")
+	}
+	for i, path := range primepath.FilterPrimePaths(paths) {
+		names := make([]string, len(path))
+		for j, n := range path {
+			names[j] = cg.Names[n]
+		}
+		fmt.Fprintf(w, "  %d: %v\n       %s\n", i+1, path, strings.Join(names, " -> "))
+	}
+	fmt.Fprintln(w)
+}
+
+// followCall reports whether the call graph keeps calls to fn, whose
+// package must match a -callgraph-include pattern, if any, and no
+// -callgraph-exclude pattern.
+func (r *runner) followCall(fn *types.Func) bool {
+	var pkgPath string
+	if fn.Pkg() != nil {
+		pkgPath = fn.Pkg().Path()
+	}
+	if r.opts.callInclude != nil && !matchPackage(r.opts.callInclude, pkgPath) {
+		return false
+	}
+	return !matchPackage(r.opts.callExclude, pkgPath)
+}
+
+// matchPackage reports whether pkgPath matches one of patterns, globs as
+// understood by path.Match or std for the standard library, whose import
+// paths have no dot in their first element.
+func matchPackage(patterns []string, pkgPath string) bool {
+	for _, pattern := range patterns {
+		if pattern == "std" {
+			first, _, _ := strings.Cut(pkgPath, "/")
+			if pkgPath != "" && !strings.Contains(first, ".") {
+				return true
+			}
+		} else if ok, _ := path.Match(pattern, pkgPath); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+// Command primepathvet runs the primepath analyzer as a standalone vet tool.
+package main
+
+import (
+	"github.com/amirkhaki/primepathfinder/analyzer"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(analyzer.Analyzer)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// ANSI SGR codes used by the text output.
+const (
+	colorBlock   = "36" // cyan
+	colorEdge    = "33" // yellow
+	colorNumber  = "1"  // bold
+	colorInitial = "32" // green
+	colorFinal   = "31" // red
+)
+
+// palette colors text output when enabled.
+type palette struct {
+	enabled bool
+	initial map[int]bool
+	final   map[int]bool
+}
+
+func (p *palette) paint(code, s string) string {
+	if !p.enabled {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// node renders graph node n, colored if it is an initial or final node.
+func (p *palette) node(n int) string {
+	s := strconv.Itoa(n)
+	switch {
+	case p.initial[n]:
+		return p.paint(colorInitial, s)
+	case p.final[n]:
+		return p.paint(colorFinal, s)
+	}
+	return s
+}
+
+// useColor reports whether output to w should be colored: w must be a
+// terminal, NO_COLOR unset and -no-color not given.
+func useColor(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
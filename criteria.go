@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+var criterionTitles = map[string]string{
+	"node":     "Node Requirements",
+	"edge":     "Edge Requirements",
+	"edgepair": "Edge-Pair Requirements",
+	"prime":    "Prime Paths",
+}
+
+func checkCriterion(criterion string) error {
+	if _, ok := criterionTitles[criterion]; !ok {
+		return fmt.Errorf("unknown criterion %q", criterion)
+	}
+	return nil
+}
+
+func requirements(criterion string, graph primepath.Graph, primePaths [][]int) [][]int {
+	switch criterion {
+	case "node":
+		var reqs [][]int
+		for _, n := range primepath.NodeRequirements(graph) {
+			reqs = append(reqs, []int{n})
+		}
+		return reqs
+	case "edge":
+		var reqs [][]int
+		for _, e := range primepath.EdgeRequirements(graph) {
+			reqs = append(reqs, []int{e[0], e[1]})
+		}
+		return reqs
+	case "edgepair":
+		var reqs [][]int
+		for _, p := range primepath.EdgePairRequirements(graph) {
+			reqs = append(reqs, []int{p[0], p[1], p[2]})
+		}
+		return reqs
+	}
+	return primePaths
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+	"golang.org/x/tools/go/cfg"
+)
+
+var csvHeader = []string{"file", "function", "requirement_index", "path", "start_line", "end_line", "toured", "function_line", "function_column"}
+
+// csvFormatter prints a row per requirement under a single header row. The
+// path lists the CFG blocks it runs through, and toured is left empty
+// without -coverprofile. The position of the function comes last, so the
+// columns before it keep their places.
+type csvFormatter struct {
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVFormatter(w io.Writer) *csvFormatter {
+	return &csvFormatter{w: csv.NewWriter(w)}
+}
+
+func (f *csvFormatter) writeHeader() {
+	if !f.header {
+		f.w.Write(csvHeader)
+		f.header = true
+	}
+}
+
+func (f *csvFormatter) Function(r *funcResult) error {
+	f.writeHeader()
+	for i, path := range r.Requirements {
+		nodes := r.ExpandPath(path)
+		blocks := make([]*cfg.Block, len(nodes))
+		indices := make([]string, len(nodes))
+		for j, n := range nodes {
+			blocks[j] = r.Blocks[n]
+			indices[j] = strconv.Itoa(int(blocks[j].Index))
+		}
+		start, end := blocksLines(r.Fset, blocks)
+		var toured string
+		if r.Coverage != nil {
+			toured = strconv.FormatBool(r.Coverage[i] == primepath.FullyCovered)
+		}
+		f.w.Write([]string{
+			r.File,
+			r.Func,
+			strconv.Itoa(i + 1),
+			strings.Join(indices, ";"),
+			strconv.Itoa(start),
+			strconv.Itoa(end),
+			toured,
+			strconv.Itoa(r.Pos.Line),
+			strconv.Itoa(r.Pos.Column),
+		})
+	}
+	f.w.Flush()
+	return f.w.Error()
+}
+
+func (f *csvFormatter) Close() error {
+	f.writeHeader()
+	f.w.Flush()
+	return f.w.Error()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+// diffFunc holds the prime paths of one version of a function, as
+// sequences of CFG block indices, with the lines of each block on them, "-"
+// for empty blocks.
+type diffFunc struct {
+	paths [][]int
+	lines []string
+}
+
+// runDiff implements "primepathfinder diff old.go new.go".
+func runDiff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	var config primepath.Config
+	fs.BoolVar(&config.PanicAsExit, "panic-as-exit", false, "treat calls to panic as never returning")
+	noReturn := fs.String("no-return", "", "comma-separated `funcs` whose calls never return, e.g. log.Fatal,os.Exit")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s diff [flags] <old.go> <new.go>\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	config.NoReturn = splitList(*noReturn)
+
+	oldNames, oldFuncs, err := diffFuncs(fs.Arg(0), &config)
+	if err != nil {
+		return err
+	}
+	newNames, newFuncs, err := diffFuncs(fs.Arg(1), &config)
+	if err != nil {
+		return err
+	}
+
+	names := oldNames
+	for _, name := range newNames {
+		if _, ok := oldFuncs[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	unchanged := 0
+	for _, name := range names {
+		before, inOld := oldFuncs[name]
+		after, inNew := newFuncs[name]
+		switch {
+		case !inNew:
+			fmt.Printf("=== Function: %s (removed) ===\n", name)
+			printDiffPaths(os.Stdout, "-", before, nil)
+		case !inOld:
+			fmt.Printf("=== Function: %s (added) ===\n", name)
+			printDiffPaths(os.Stdout, "+", after, nil)
+		default:
+			removed, added := make(map[string]bool), make(map[string]bool)
+			for _, path := range before.paths {
+				removed[pathString(path)] = true
+			}
+			for _, path := range after.paths {
+				key := pathString(path)
+				if removed[key] {
+					delete(removed, key)
+				} else {
+					added[key] = true
+				}
+			}
+			if len(removed) == 0 && len(added) == 0 {
+				unchanged++
+				continue
+			}
+			fmt.Printf("=== Function: %s ===\n", name)
+			printDiffPaths(os.Stdout, "-", before, removed)
+			printDiffPaths(os.Stdout, "+", after, added)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%d functions unchanged\n", unchanged)
+	return nil
+}
+
+// printDiffPaths prints the paths of fn in only, or all of them if only is
+// nil, each marked with sign.
+func printDiffPaths(w io.Writer, sign string, fn diffFunc, only map[string]bool) {
+	for i, path := range fn.paths {
+		if only == nil || only[pathString(path)] {
+			fmt.Fprintf(w, "%s %v %s\n", sign, path, fn.lines[i])
+		}
+	}
+}
+
+// diffFuncs returns the prime paths of each function in filename, keyed by
+// name, along with the names in file order.
+func diffFuncs(filename string, config *primepath.Config) ([]string, map[string]diffFunc, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	funcs := make(map[string]diffFunc)
+	for _, fn := range primepath.Funcs(file) {
+		res, err := config.AnalyzeFunc(fset, filename, file, fn, primepath.Options{})
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s:%s: %w", filename, fn.Name, err)
+		}
+
+		var d diffFunc
+		for _, path := range res.PrimePaths {
+			indices := make([]int, len(path))
+			lines := make([]string, len(path))
+			for i, n := range path {
+				indices[i] = int(res.Blocks[n].Index)
+				switch start, end := primepath.BlockLines(fset, res.Blocks[n]); {
+				case start == 0:
+					lines[i] = "-"
+				case start == end:
+					lines[i] = fmt.Sprint(start)
+				default:
+					lines[i] = fmt.Sprintf("%d-%d", start, end)
+				}
+			}
+			d.paths = append(d.paths, indices)
+			d.lines = append(d.lines, "lines "+strings.Join(lines, " "))
+		}
+		names = append(names, fn.Name)
+		funcs[fn.Name] = d
+	}
+	return names, funcs, nil
+}
+
+func pathString(path []int) string {
+	return fmt.Sprint(path)
+}
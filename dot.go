@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"strconv"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+	"golang.org/x/tools/go/cfg"
+)
+
+type dotFormatter struct {
+	w         io.Writer
+	highlight int
+	// cluster groups the blocks of each control statement into a subgraph.
+	cluster bool
+}
+
+func (f *dotFormatter) Function(r *funcResult) error {
+	graph := r.Graph
+
+	var path []int
+	if f.highlight > 0 {
+		if len(r.Requirements) == 0 {
+			return fmt.Errorf("%s:%s: -highlight %d given, but the function has no requirements to highlight",
+				r.File, r.label(), f.highlight)
+		}
+		if f.highlight > len(r.Requirements) {
+			return fmt.Errorf("%s:%s: -highlight %d out of range, valid range is 1-%d",
+				r.File, r.label(), f.highlight, len(r.Requirements))
+		}
+		path = r.Requirements[f.highlight-1]
+	}
+	onPath := nodeSet(path)
+	pathEdges := make(map[[2]int]bool)
+	for i := 1; i < len(path); i++ {
+		pathEdges[[2]int{path[i-1], path[i]}] = true
+	}
+
+	fmt.Fprintf(f.w, "digraph %s {\n", strconv.Quote(r.File+":"+r.label()))
+	if path != nil {
+		fmt.Fprintln(f.w, "  node [shape=circle, color=gray, fontcolor=gray];")
+		fmt.Fprintln(f.w, "  edge [color=gray];")
+	} else {
+		fmt.Fprintln(f.w, "  node [shape=circle];")
+	}
+
+	initial := nodeSet(primepath.InitialNodes(graph))
+	final := nodeSet(primepath.FinalNodes(graph))
+	writeNode := func(indent string, n int) {
+		attrs := fmt.Sprintf("label=\"%d\"", n)
+		if initial[n] {
+			attrs += ", style=filled, fillcolor=palegreen"
+		}
+		if final[n] {
+			attrs += ", shape=doublecircle"
+		}
+		if onPath[n] {
+			attrs += ", color=red, fontcolor=red, penwidth=2"
+		}
+		fmt.Fprintf(f.w, "%sn%d [%s];\n", indent, n, attrs)
+	}
+	if f.cluster {
+		writeClusters(f.w, r, writeNode)
+	} else {
+		for n := range graph {
+			writeNode("  ", n)
+		}
+	}
+
+	for from, succs := range graph {
+		for _, to := range succs {
+			if pathEdges[[2]int{from, to}] {
+				fmt.Fprintf(f.w, "  n%d -> n%d [color=red, penwidth=2];\n", from, to)
+			} else {
+				fmt.Fprintf(f.w, "  n%d -> n%d;\n", from, to)
+			}
+		}
+	}
+	_, err := fmt.Fprintln(f.w, "}")
+	return err
+}
+
+func (f *dotFormatter) Close() error {
+	return nil
+}
+
+// writeClusters writes the nodes of r, grouping those that belong to a
+// control statement into nested subgraph clusters labeled with the
+// statement and its line. Switch and select statements get a cluster
+// around those of their cases.
+func writeClusters(w io.Writer, r *funcResult, writeNode func(indent string, n int)) {
+	stmts := controlStmts(r.CFG)
+
+	// parent maps each statement to the innermost other one containing
+	// it.
+	parent := make(map[ast.Stmt]ast.Stmt)
+	for _, stmt := range stmts {
+		if outer := innermostStmt(stmts, stmt.Pos(), stmt.End(), stmt); outer != nil {
+			parent[stmt] = outer
+		}
+	}
+
+	members := make(map[ast.Stmt][]int)
+	used := make(map[ast.Stmt]bool)
+	var top []int
+	for n := range r.Graph {
+		stmt := clusterStmt(stmts, r.NodeBlocks(n))
+		if stmt == nil {
+			top = append(top, n)
+			continue
+		}
+		members[stmt] = append(members[stmt], n)
+		for ; stmt != nil && !used[stmt]; stmt = parent[stmt] {
+			used[stmt] = true
+		}
+	}
+
+	children := make(map[ast.Stmt][]ast.Stmt)
+	var roots []ast.Stmt
+	for _, stmt := range stmts {
+		switch {
+		case !used[stmt]:
+		case parent[stmt] == nil:
+			roots = append(roots, stmt)
+		default:
+			children[parent[stmt]] = append(children[parent[stmt]], stmt)
+		}
+	}
+
+	id := 0
+	var writeCluster func(indent string, stmt ast.Stmt)
+	writeCluster = func(indent string, stmt ast.Stmt) {
+		fmt.Fprintf(w, "%ssubgraph cluster_%d {\n", indent, id)
+		id++
+		label := fmt.Sprintf("%s (line %d)", stmtKind(stmt), r.Fset.Position(stmt.Pos()).Line)
+		fmt.Fprintf(w, "%s  label=%s;\n", indent, strconv.Quote(label))
+		for _, n := range members[stmt] {
+			writeNode(indent+"  ", n)
+		}
+		for _, child := range children[stmt] {
+			writeCluster(indent+"  ", child)
+		}
+		fmt.Fprintf(w, "%s}\n", indent)
+	}
+	for _, n := range top {
+		writeNode("  ", n)
+	}
+	for _, stmt := range roots {
+		writeCluster("  ", stmt)
+	}
+}
+
+// controlStmts returns the statements of the function g is the CFG of that
+// get a cluster, in source order, leaving out function literals.
+func controlStmts(g *cfg.CFG) []ast.Stmt {
+	var stmts []ast.Stmt
+	for _, block := range g.Blocks {
+		if block.Kind != cfg.KindBody {
+			continue
+		}
+		ast.Inspect(block.Stmt, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ForStmt, *ast.RangeStmt, *ast.IfStmt,
+				*ast.SwitchStmt, *ast.TypeSwitchStmt, *ast.SelectStmt,
+				*ast.CaseClause, *ast.CommClause:
+				stmts = append(stmts, n.(ast.Stmt))
+			}
+			return true
+		})
+	}
+	return stmts
+}
+
+// clusterStmt returns the statement of stmts whose cluster the node made of
+// blocks belongs in: the innermost one containing the statements of the
+// blocks. Blocks without statements belong to the statement they were
+// created for, or to the one around it if they follow it or, for an empty
+// next case, come after its last case. It returns nil for nodes outside
+// any.
+func clusterStmt(stmts []ast.Stmt, blocks []*cfg.Block) ast.Stmt {
+	pos, end := token.NoPos, token.NoPos
+	for _, block := range blocks {
+		for _, node := range block.Nodes {
+			if pos == token.NoPos || node.Pos() < pos {
+				pos = node.Pos()
+			}
+			end = max(end, node.End())
+		}
+	}
+	if pos != token.NoPos {
+		return innermostStmt(stmts, pos, end, nil)
+	}
+
+	block := blocks[0]
+	if block.Stmt == nil {
+		return nil
+	}
+	switch block.Kind {
+	case cfg.KindForDone, cfg.KindIfDone, cfg.KindRangeDone,
+		cfg.KindSelectDone, cfg.KindSelectAfterCase, cfg.KindSwitchDone,
+		cfg.KindSwitchNextCase, cfg.KindLabel:
+		return innermostStmt(stmts, block.Stmt.Pos(), block.Stmt.End(), block.Stmt)
+	}
+	return innermostStmt(stmts, block.Stmt.Pos(), block.Stmt.End(), nil)
+}
+
+// innermostStmt returns the shortest of stmts other than except that spans
+// pos to end, or nil if there is none.
+func innermostStmt(stmts []ast.Stmt, pos, end token.Pos, except ast.Stmt) ast.Stmt {
+	var inner ast.Stmt
+	for _, stmt := range stmts {
+		if stmt != except && stmt.Pos() <= pos && end <= stmt.End() &&
+			(inner == nil || stmt.End()-stmt.Pos() < inner.End()-inner.Pos()) {
+			inner = stmt
+		}
+	}
+	return inner
+}
+
+func stmtKind(stmt ast.Stmt) string {
+	switch stmt := stmt.(type) {
+	case *ast.ForStmt:
+		return "for"
+	case *ast.RangeStmt:
+		return "for range"
+	case *ast.IfStmt:
+		return "if"
+	case *ast.SwitchStmt:
+		return "switch"
+	case *ast.TypeSwitchStmt:
+		return "type switch"
+	case *ast.SelectStmt:
+		return "select"
+	case *ast.CaseClause:
+		if stmt.List == nil {
+			return "default"
+		}
+		return "case"
+	case *ast.CommClause:
+		if stmt.Comm == nil {
+			return "default"
+		}
+		return "select case"
+	}
+	return fmt.Sprintf("%T", stmt)
+}
+
+func nodeSet(nodes []int) map[int]bool {
+	set := make(map[int]bool, len(nodes))
+	for _, n := range nodes {
+		set[n] = true
+	}
+	return set
+}
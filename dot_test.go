@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDOTHighlightRange(t *testing.T) {
+	tests := []struct {
+		name, criterion, file string
+		want                  string
+	}{
+		// A single block has no edges to cover.
+		{"no requirements", "edge", "trivial.go", "has no requirements to highlight"},
+		{"out of range", "prime", "returns.go", "valid range is 1-3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &runner{
+				opts: options{criterion: tt.criterion},
+				out:  &dotFormatter{w: io.Discard, highlight: 4},
+			}
+			err := r.processFile(filepath.Join("testdata", tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+// edgelistFormatter prints the edges of each graph as "from to" lines
+// under a "# file:name" comment, separating functions with a blank line.
+type edgelistFormatter struct {
+	w         io.Writer
+	functions int
+}
+
+func (f *edgelistFormatter) Function(r *funcResult) error {
+	if f.functions > 0 {
+		fmt.Fprintln(f.w)
+	}
+	f.functions++
+
+	_, err := fmt.Fprintf(f.w, "# %s:%s\n", r.File, r.label())
+	for _, e := range primepath.EdgeRequirements(r.Graph) {
+		_, err = fmt.Fprintf(f.w, "%d %d\n", e[0], e[1])
+	}
+	return err
+}
+
+func (f *edgelistFormatter) Close() error {
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// explainOrder lists the criteria from strongest to weakest, each
+// subsuming those after it.
+var explainOrder = []string{"prime", "edgepair", "edge", "node"}
+
+// explainFormatter prints the number of requirements of every criterion
+// for each function, and once at the end how the criteria relate.
+type explainFormatter struct {
+	w         io.Writer
+	functions int
+}
+
+func (f *explainFormatter) Function(r *funcResult) error {
+	f.functions++
+	fmt.Fprintf(f.w, "=== Function: %s:%s (%s) ===\n", r.File, r.label(), r.Pos)
+	for _, criterion := range explainOrder {
+		n := len(requirements(criterion, r.Graph, r.PrimePaths))
+		fmt.Fprintf(f.w, "  %-24s %d\n", criterionTitles[criterion]+":", n)
+	}
+	if r.Truncated {
+		fmt.Fprintln(f.w, "  (prime paths incomplete: path enumeration was truncated)")
+	}
+	_, err := fmt.Fprintln(f.w)
+	return err
+}
+
+func (f *explainFormatter) Close() error {
+	if f.functions == 0 {
+		return nil
+	}
+	_, err := fmt.Fprint(f.w, `Prime path coverage subsumes edge-pair coverage, which subsumes edge
+coverage, which subsumes node coverage: test paths touring every prime
+path also cover every edge pair, edge and node, but not the other way
+round. The one exception is a node that is its own successor: the edge
+pair [n n n] goes around it twice, which is not a simple path, so no
+prime path contains it. A stronger criterion can have fewer
+requirements, since each is a longer path; what grows is the number and
+length of the test paths needed to satisfy them.
+`)
+	return err
+}
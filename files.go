@@ -0,0 +1,85 @@
+package main
+
+import (
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// collectFiles returns the Go files arg names: arg itself if it is a file or
+// "-", or the files under it if it is a directory. Walking a directory
+// skips test files unless includeTests is set, files whose build
+// constraints or GOOS/GOARCH file name suffix ctxt does not satisfy, and
+// generated files unless includeGenerated is set, which it counts.
+func collectFiles(arg string, includeTests, includeGenerated bool, ctxt *build.Context) (files []string, generated int, err error) {
+	if arg == "-" {
+		return []string{arg}, 0, nil
+	}
+
+	info, err := os.Stat(arg)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !info.IsDir() {
+		return []string{arg}, 0, nil
+	}
+
+	err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != arg && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isGoFile(d.Name(), includeTests) {
+			return nil
+		}
+		match, err := ctxt.MatchFile(filepath.Dir(path), d.Name())
+		if !match || err != nil {
+			return err
+		}
+		if !includeGenerated && isGenerated(path) {
+			generated++
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	return files, generated, err
+}
+
+// isGenerated reports whether the file at path has the "// Code generated
+// ... DO NOT EDIT." comment marking generated code. Files that cannot be
+// read or parsed are not, leaving the error to their analysis.
+func isGenerated(path string) bool {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+	return err == nil && ast.IsGenerated(file)
+}
+
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") ||
+		name == "testdata" || name == "vendor"
+}
+
+func isGoFile(name string, includeTests bool) bool {
+	if !strings.HasSuffix(name, ".go") {
+		return false
+	}
+	return includeTests || !strings.HasSuffix(name, "_test.go")
+}
+
+// buildContext returns the default build context, which follows GOOS,
+// GOARCH and the other go environment variables, with tags enabled.
+func buildContext(tags []string) *build.Context {
+	ctxt := build.Default
+	ctxt.BuildTags = tags
+	return &ctxt
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"io"
+	"strings"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+type formatter interface {
+	Function(r *funcResult) error
+	Close() error
+}
+
+func newFormatter(name string, w io.Writer, opts options) (formatter, error) {
+	if opts.highlight != 0 && name != "dot" {
+		return nil, fmt.Errorf("-highlight requires -format dot")
+	}
+	if opts.dotCluster && name != "dot" {
+		return nil, fmt.Errorf("-dot-cluster requires -format dot")
+	}
+	if opts.signature && name != "text" {
+		return nil, fmt.Errorf("-signature requires -format text")
+	}
+	if opts.source && name != "text" {
+		return nil, fmt.Errorf("-source requires -format text")
+	}
+	if opts.highlight < 0 {
+		return nil, fmt.Errorf("-highlight must be positive")
+	}
+
+	if opts.verbose < 0 || opts.verbose > 2 {
+		return nil, fmt.Errorf("-v must be 0, 1 or 2")
+	}
+
+	if opts.genTests {
+		if name != "text" || opts.summary || opts.explain || opts.stats {
+			return nil, fmt.Errorf("-gen-tests cannot be combined with -format, -summary, -explain or -stats")
+		}
+		return &testsFormatter{w: w}, nil
+	}
+
+	if opts.stats {
+		if name != "text" || opts.summary || opts.explain {
+			return nil, fmt.Errorf("-stats cannot be combined with -format, -summary or -explain")
+		}
+		return &statsFormatter{w: w, total: make(lengthHistogram)}, nil
+	}
+
+	if opts.explain {
+		if name != "text" || opts.summary {
+			return nil, fmt.Errorf("-explain cannot be combined with -format or -summary")
+		}
+		return &explainFormatter{w: w}, nil
+	}
+
+	if opts.summary {
+		if name != "text" && name != "json" {
+			return nil, fmt.Errorf("-summary requires -format text or json")
+		}
+		return &summaryFormatter{w: w, json: name == "json"}, nil
+	}
+
+	switch name {
+	case "text":
+		return &textFormatter{w: w, verbose: opts.verbose, color: useColor(w, opts.noColor), source: opts.source, cycles: opts.analysis.Cycles}, nil
+	case "json":
+		return &jsonFormatter{w: w}, nil
+	case "jsonl":
+		return newJSONLFormatter(w), nil
+	case "dot":
+		return &dotFormatter{w: w, highlight: opts.highlight, cluster: opts.dotCluster}, nil
+	case "edgelist":
+		return &edgelistFormatter{w: w}, nil
+	case "mermaid":
+		return &mermaidFormatter{w: w}, nil
+	case "csv":
+		return newCSVFormatter(w), nil
+	}
+	return nil, fmt.Errorf("unknown format %q", name)
+}
+
+func nodeString(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	printer.Fprint(&buf, fset, node)
+	return buf.String()
+}
+
+// funcSignature prints the declaration of fn without its body, or the type
+// of a function literal.
+func funcSignature(fset *token.FileSet, fn primepath.Func) string {
+	decl, ok := fn.Node.(*ast.FuncDecl)
+	if !ok {
+		return nodeString(fset, fn.Node.(*ast.FuncLit).Type)
+	}
+	sig := *decl
+	sig.Doc, sig.Body = nil, nil
+	return nodeString(fset, &sig)
+}
+
+// edgeKinds maps the edges of graph to their kinds, as classified by
+// primepath.ClassifyEdges.
+func edgeKinds(graph [][]int) map[[2]int]primepath.EdgeKind {
+	kinds := make(map[[2]int]primepath.EdgeKind)
+	for from, succKinds := range primepath.ClassifyEdges(graph) {
+		for i, kind := range succKinds {
+			kinds[[2]int{from, graph[from][i]}] = kind
+		}
+	}
+	return kinds
+}
+
+// typeParams prints the type parameter list of fn, such as "[T, U any]",
+// or "" if fn is not a generic function.
+func typeParams(fset *token.FileSet, fn primepath.Func) string {
+	decl, ok := fn.Node.(*ast.FuncDecl)
+	if !ok || decl.Type.TypeParams == nil {
+		return ""
+	}
+	fields := make([]string, len(decl.Type.TypeParams.List))
+	for i, field := range decl.Type.TypeParams.List {
+		names := make([]string, len(field.Names))
+		for j, name := range field.Names {
+			names[j] = name.Name
+		}
+		fields[i] = strings.Join(names, ", ") + " " + nodeString(fset, field.Type)
+	}
+	return "[" + strings.Join(fields, ", ") + "]"
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFormatterLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		new  func(w io.Writer) formatter
+	}{
+		{"text", func(w io.Writer) formatter { return &textFormatter{w: w} }},
+		{"summary", func(w io.Writer) formatter { return &summaryFormatter{w: w} }},
+		{"stats", func(w io.Writer) formatter { return &statsFormatter{w: w, total: make(lengthHistogram)} }},
+		{"explain", func(w io.Writer) formatter { return &explainFormatter{w: w} }},
+		{"dot", func(w io.Writer) formatter { return &dotFormatter{w: w} }},
+		{"mermaid", func(w io.Writer) formatter { return &mermaidFormatter{w: w} }},
+		{"edgelist", func(w io.Writer) formatter { return &edgelistFormatter{w: w} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			analyze(t, "generics.go", tt.new(&buf))
+			// Every formatter names Map with its type parameters.
+			if want := "Map[T, U any]"; !strings.Contains(buf.String(), want) {
+				t.Errorf("output does not contain %q:\n%s", want, buf.String())
+			}
+		})
+	}
+}
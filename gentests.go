@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+// testsFormatter collects the functions of a single package and writes a
+// test skeleton for them on Close.
+type testsFormatter struct {
+	w       io.Writer
+	pkg     string
+	buf     bytes.Buffer
+	used    map[string]bool
+	written bool
+}
+
+func (f *testsFormatter) Function(r *funcResult) error {
+	if f.used == nil {
+		f.pkg = r.Package
+		f.used = make(map[string]bool)
+	}
+	if r.Package != f.pkg {
+		return fmt.Errorf("%s: -gen-tests needs files from a single package, got %s and %s",
+			r.File, f.pkg, r.Package)
+	}
+
+	kind, paths := criterionTitles[r.Criterion], r.Requirements
+	if r.EntryOnly {
+		kind = "Entry Paths"
+	}
+	if r.TestPaths != nil {
+		kind, paths = "Minimal Test Paths", r.TestPaths
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	f.written = true
+
+	name := f.testName(r.Func)
+	fmt.Fprintf(&f.buf, "\n// %s covers the %s of %s in %s.\n", name, strings.ToLower(kind), r.label(), r.File)
+	fmt.Fprintf(&f.buf, "func %s(t *testing.T) {\n", name)
+	fmt.Fprintln(&f.buf, "\ttests := []struct {\n\t\tname string\n\t}{")
+	for i, path := range paths {
+		fmt.Fprintf(&f.buf, "\t\t// Blocks %v, %s.\n", pathBlocks(r, path), pathLines(r, path))
+		fmt.Fprintf(&f.buf, "\t\t{name: %q},\n", fmt.Sprintf("path %d", i+1))
+	}
+	fmt.Fprintln(&f.buf, "\t}")
+	fmt.Fprintln(&f.buf, "\tfor _, tt := range tests {")
+	fmt.Fprintln(&f.buf, "\t\tt.Run(tt.name, func(t *testing.T) {")
+	fmt.Fprintln(&f.buf, "\t\t\tt.Skip(\"TODO: drive execution along this path\")")
+	fmt.Fprintln(&f.buf, "\t\t})")
+	fmt.Fprintln(&f.buf, "\t}")
+	fmt.Fprintln(&f.buf, "}")
+	return nil
+}
+
+func (f *testsFormatter) Close() error {
+	if !f.written {
+		return nil
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "package %s\n\nimport \"testing\"\n", f.pkg)
+	src.Write(f.buf.Bytes())
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = f.w.Write(out)
+	return err
+}
+
+// testName derives a unique test function name from a function name such
+// as "(*T).M", "(*Stack[E]).Push" or "f$1".
+func (f *testsFormatter) testName(funcName string) string {
+	var b strings.Builder
+	b.WriteString("Test")
+	upper := true
+	for _, r := range stripTypeParams(funcName) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		case r == '.' || r == '$':
+			b.WriteByte('_')
+		}
+	}
+
+	name := b.String()
+	for i := 2; f.used[name]; i++ {
+		name = fmt.Sprintf("%s%d", b.String(), i)
+	}
+	f.used[name] = true
+	return name
+}
+
+// pathBlocks returns the indices of the CFG blocks path runs through.
+func pathBlocks(r *funcResult, path []int) []int32 {
+	var blocks []int32
+	for _, n := range r.ExpandPath(path) {
+		blocks = append(blocks, r.Blocks[n].Index)
+	}
+	return blocks
+}
+
+func pathLines(r *funcResult, path []int) string {
+	var lines []string
+	for _, n := range r.ExpandPath(path) {
+		start, end := primepath.BlockLines(r.Fset, r.Blocks[n])
+		switch {
+		case start == 0:
+			continue
+		case start == end:
+			lines = append(lines, fmt.Sprint(start))
+		default:
+			lines = append(lines, fmt.Sprintf("%d-%d", start, end))
+		}
+	}
+	if len(lines) == 0 {
+		return "no statements"
+	}
+	return "lines " + strings.Join(lines, ", ")
+}
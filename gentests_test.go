@@ -0,0 +1,17 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestGenTestsBlocks(t *testing.T) {
+	var buf bytes.Buffer
+	analyze(t, "deadcode.go", &testsFormatter{w: &buf})
+	// A dead block shifts the nodes after it, so the nodes [0 2 5 3 6] are
+	// these blocks.
+	if want := "// Blocks [0 2 6 4 7]"; !strings.Contains(buf.String(), want) {
+		t.Errorf("generated tests do not contain %q:\n%s", want, buf.String())
+	}
+}
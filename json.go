@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+type jsonBlock struct {
+	Index int   `json:"index"`
+	Live  bool  `json:"live"`
+	Succs []int `json:"succs"`
+	// DeadSuccs lists the successors in Succs that are not live, which the
+	// graph leaves out along with their edges.
+	DeadSuccs []int    `json:"dead_succs,omitempty"`
+	Nodes     []string `json:"nodes"`
+}
+
+type jsonDeadBlock struct {
+	Block     int `json:"block"`
+	StartLine int `json:"start_line"`
+	EndLine   int `json:"end_line"`
+}
+
+type jsonTour struct {
+	Path []int  `json:"path"`
+	Kind string `json:"kind"`
+}
+
+type jsonInfeasible struct {
+	Requirement int    `json:"requirement"`
+	Reason      string `json:"reason"`
+}
+
+type jsonEdgePrimePaths struct {
+	Edge [2]int `json:"edge"`
+	// Kind classifies Edge as a tree, forward, back or cross edge.
+	Kind string `json:"kind"`
+	// PrimePaths numbers the prime paths traversing Edge from 1.
+	PrimePaths []int `json:"prime_paths"`
+}
+
+type jsonFunction struct {
+	File       string      `json:"file"`
+	Function   string      `json:"function"`
+	TypeParams string      `json:"type_params,omitempty"`
+	Line       int         `json:"line"`
+	Column     int         `json:"column"`
+	Blocks     []jsonBlock `json:"blocks"`
+	GraphNodes []int       `json:"graph_nodes"`
+	// Reversed reports that paths run against the edges, with -reverse.
+	Reversed bool `json:"reversed,omitempty"`
+	// Chains lists the blocks each graph node merges, with -collapse.
+	Chains      [][]int         `json:"chains,omitempty"`
+	DeadBlocks  []jsonDeadBlock `json:"dead_blocks,omitempty"`
+	MergedCases int             `json:"merged_cases,omitempty"`
+	Criterion   string          `json:"criterion"`
+	SimplePaths [][]int         `json:"simple_paths,omitempty"`
+	Cycles      [][]int         `json:"cycles,omitempty"`
+	PrimePaths  [][]int         `json:"prime_paths,omitempty"`
+	// EntryOnly reports that PrimePaths are the longest paths from the
+	// entry, with -entry-only.
+	EntryOnly bool `json:"entry_only,omitempty"`
+	// GraphOnly reports that no paths were computed, with -graph-only.
+	GraphOnly    bool             `json:"graph_only,omitempty"`
+	Truncated    bool             `json:"truncated,omitempty"`
+	Requirements [][]int          `json:"requirements,omitempty"`
+	Infeasible   []jsonInfeasible `json:"infeasible,omitempty"`
+	// Coverage classifies each requirement against the coverage profile.
+	Coverage        []string `json:"coverage,omitempty"`
+	CoveragePercent *float64 `json:"coverage_percent,omitempty"`
+	// Hotness estimates how often each requirement ran, from the counts of
+	// the coverage profile.
+	Hotness []int `json:"hotness,omitempty"`
+	// EdgePrimePaths lists every edge of the graph, including any that no
+	// prime path traverses.
+	EdgePrimePaths []jsonEdgePrimePaths `json:"edge_prime_paths"`
+	Tours          []jsonTour           `json:"tours,omitempty"`
+	TestPaths      [][]int              `json:"test_paths,omitempty"`
+}
+
+type jsonFormatter struct {
+	w         io.Writer
+	functions []jsonFunction
+}
+
+func (f *jsonFormatter) Function(r *funcResult) error {
+	f.functions = append(f.functions, newJSONFunction(r))
+	return nil
+}
+
+func (f *jsonFormatter) Close() error {
+	functions := f.functions
+	if functions == nil {
+		functions = []jsonFunction{}
+	}
+	enc := json.NewEncoder(f.w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(functions)
+}
+
+// jsonlFormatter writes each function as a JSON object on a line of its
+// own as soon as it is analyzed, instead of holding them for one array.
+type jsonlFormatter struct {
+	enc *json.Encoder
+}
+
+func newJSONLFormatter(w io.Writer) *jsonlFormatter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlFormatter{enc: enc}
+}
+
+func (f *jsonlFormatter) Function(r *funcResult) error {
+	return f.enc.Encode(newJSONFunction(r))
+}
+
+func (f *jsonlFormatter) Close() error {
+	return nil
+}
+
+func newJSONFunction(r *funcResult) jsonFunction {
+	fn := jsonFunction{
+		File:        r.File,
+		Function:    r.Func,
+		TypeParams:  r.TypeParams,
+		Line:        r.Pos.Line,
+		Column:      r.Pos.Column,
+		Blocks:      make([]jsonBlock, 0, len(r.CFG.Blocks)),
+		GraphNodes:  make([]int, len(r.Graph)),
+		Criterion:   r.Criterion,
+		Truncated:   r.Truncated,
+		EntryOnly:   r.EntryOnly,
+		GraphOnly:   r.GraphOnly,
+		Reversed:    r.Reversed,
+		MergedCases: r.MergedCases,
+		SimplePaths: r.SimplePaths,
+		Cycles:      r.Cycles,
+		TestPaths:   r.TestPaths,
+	}
+	if r.Criterion == "prime" {
+		fn.PrimePaths = r.Requirements
+	} else {
+		fn.Requirements = r.Requirements
+	}
+
+	for i := range r.Requirements {
+		if reason, ok := r.Infeasible[i]; ok {
+			fn.Infeasible = append(fn.Infeasible, jsonInfeasible{Requirement: i + 1, Reason: reason})
+		}
+	}
+
+	if r.Coverage != nil {
+		for _, c := range r.Coverage {
+			fn.Coverage = append(fn.Coverage, c.String())
+		}
+		percent := coveragePercent(r.Coverage)
+		fn.CoveragePercent = &percent
+		fn.Hotness = r.Hotness
+	}
+
+	index := primepath.EdgeToPrimePaths(r.PrimePaths)
+	fn.EdgePrimePaths = []jsonEdgePrimePaths{}
+	kinds := edgeKinds(r.Graph)
+	for _, edge := range primepath.EdgeRequirements(r.Graph) {
+		e := jsonEdgePrimePaths{Edge: edge, Kind: kinds[edge].String(), PrimePaths: []int{}}
+		for _, i := range index[edge] {
+			e.PrimePaths = append(e.PrimePaths, i+1)
+		}
+		fn.EdgePrimePaths = append(fn.EdgePrimePaths, e)
+	}
+
+	for _, block := range r.Dead {
+		start, end := primepath.BlockLines(r.Fset, block)
+		fn.DeadBlocks = append(fn.DeadBlocks, jsonDeadBlock{Block: int(block.Index), StartLine: start, EndLine: end})
+	}
+
+	for _, tour := range r.Tours {
+		fn.Tours = append(fn.Tours, jsonTour{Path: tour.Path, Kind: tour.Kind.String()})
+	}
+
+	for n := range r.Graph {
+		blocks := r.NodeBlocks(n)
+		fn.GraphNodes[n] = int(blocks[0].Index)
+		if r.Chains != nil {
+			chain := make([]int, len(blocks))
+			for i, block := range blocks {
+				chain[i] = int(block.Index)
+			}
+			fn.Chains = append(fn.Chains, chain)
+		}
+	}
+
+	for _, block := range primepath.SortedBlocks(r.CFG) {
+		b := jsonBlock{
+			Index: int(block.Index),
+			Live:  block.Live,
+			Succs: make([]int, 0, len(block.Succs)),
+			Nodes: make([]string, 0, len(block.Nodes)),
+		}
+		for _, succ := range block.Succs {
+			b.Succs = append(b.Succs, int(succ.Index))
+			if !succ.Live {
+				b.DeadSuccs = append(b.DeadSuccs, int(succ.Index))
+			}
+		}
+		for _, node := range block.Nodes {
+			b.Nodes = append(b.Nodes, nodeString(r.Fset, node))
+		}
+		fn.Blocks = append(fn.Blocks, b)
+	}
+	return fn
+}
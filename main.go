@@ -1,233 +1,694 @@
 package main
 
 import (
-	"bytes"
+	"cmp"
+	"errors"
+	"flag"
 	"fmt"
 	"go/ast"
 	"go/parser"
-	"go/printer"
+	"go/scanner"
 	"go/token"
+	"io"
+	"math"
 	"os"
+	"path"
+	"slices"
+	"strings"
 
-	"golang.org/x/tools/go/cfg"
+	"github.com/amirkhaki/primepathfinder/primepath"
+	"golang.org/x/tools/cover"
 )
 
-func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <go-file>\n", os.Args[0])
-		os.Exit(1)
+type funcResult struct {
+	primepath.Result
+	// Comments maps the nodes of the file to their comments, when
+	// requested.
+	Comments ast.CommentMap
+	// Signature is the signature of the function, shown with block 0 when
+	// requested.
+	Signature string
+	// TypeParams is the type parameter list of a generic function, such as
+	// "[T, U any]", shown after its name.
+	TypeParams string
+	Criterion  string
+	// Requirements are the test requirements of Criterion, the prime paths
+	// by default.
+	Requirements [][]int
+	// Infeasible maps the indices of requirements with contradictory
+	// branch conditions to the contradiction, when requested.
+	Infeasible map[int]string
+	// Coverage classifies each requirement against a coverage profile,
+	// when one was given.
+	Coverage []primepath.Coverage
+	// Hotness estimates how often each requirement ran, from the counts
+	// of the same profile.
+	Hotness []int
+	// Tours holds a tour of each requirement, when requested.
+	Tours []primepath.Tour
+	// TestPaths is a minimal set of test paths touring the requirements,
+	// when requested.
+	TestPaths [][]int
+	// Sinks are the final nodes whose exit kind -sinks lists, when given
+	// and the function has final nodes.
+	Sinks []int
+}
+
+// label returns the name of the function followed by its type parameters,
+// as formatters title it.
+func (r *funcResult) label() string {
+	return r.Func + r.TypeParams
+}
+
+// ends returns the nodes complete test paths may end at.
+func (r *funcResult) ends() []int {
+	if r.Sinks != nil {
+		return r.Sinks
 	}
+	return primepath.ExitNodes(r.Graph)
+}
 
-	filename := os.Args[1]
+type options struct {
+	highlight int
+	criterion string
+	tours     bool
+	minimal   bool
+	cfg       primepath.Config
+	funcs     []string
+	summary   bool
+	analysis  primepath.Options
+	packages  bool
+	tests     bool
+	genTests  bool
+	verbose   int
+	noColor   bool
+	// maxPrimePaths and maxComplexity fail the run for functions over
+	// them, when positive.
+	maxPrimePaths  int
+	maxComplexity  int
+	flagInfeasible bool
+	callGraph      bool
+	dotCluster     bool
+	warnDead       bool
+	explain        bool
+	stats          bool
+	skipTrivial    bool
+	source         bool
+	signature      bool
+	generated      bool
+	sinks          map[primepath.ExitKind]bool
+	callInclude    []string
+	callExclude    []string
+	sortHot        bool
+	quiet          bool
+	tags           []string
+	comments       bool
+	profiles       []*cover.Profile
+}
 
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, filename, nil, 0)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error parsing file: %v\n", err)
-		os.Exit(1)
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if err := runDiff(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	for _, decl := range file.Decls {
-		fn, ok := decl.(*ast.FuncDecl)
-		if !ok || fn.Body == nil {
-			continue
+	var opts options
+	flag.BoolVar(&opts.generated, "include-generated", false, "include generated files, marked by a \"Code generated ... DO NOT EDIT.\" comment, when walking directories")
+	flag.BoolVar(&opts.tests, "tests", false, "include _test.go files when walking directories or loading packages")
+	tags := flag.String("tags", "", "comma-separated build `tags` used to select files when walking directories or loading packages")
+	flag.BoolVar(&opts.packages, "packages", false, "treat arguments as package patterns and load them with type information")
+	format := flag.String("format", "text", "output format: text, json, jsonl, dot, edgelist, mermaid or csv")
+	output := flag.String("o", "", "write output to `path`, or to one file per source file if path is a directory")
+	flag.IntVar(&opts.highlight, "highlight", 0, "highlight the `N`-th requirement in DOT output")
+	flag.BoolVar(&opts.dotCluster, "dot-cluster", false, "group the blocks of each for, if, switch and select into a DOT cluster")
+	flag.BoolVar(&opts.analysis.MergeEmptyCases, "merge-empty-cases", false, "merge switch cases with empty bodies into one case")
+	flag.BoolVar(&opts.analysis.Collapse, "collapse", false, "merge straight-line chains of blocks into single nodes")
+	flag.BoolVar(&opts.analysis.Reverse, "reverse", false, "compute paths over the reversed graph, from exits back to the entry")
+	flag.BoolVar(&opts.quiet, "q", false, "print only errors and violations of -max-prime-paths, -max-complexity and -warn-dead")
+	flag.BoolVar(&opts.signature, "signature", false, "show the function signature at the top of block 0 in the CFG dump and with -source")
+	flag.BoolVar(&opts.source, "source", false, "print the statements along each requirement in the order they run")
+	flag.BoolVar(&opts.skipTrivial, "skip-trivial", false, "leave out functions without branches or loops, whose single prime path is the whole function")
+	flag.BoolVar(&opts.stats, "stats", false, "print a histogram of prime path lengths per function and in total")
+	flag.BoolVar(&opts.explain, "explain", false, "compare the number of requirements of every criterion instead of listing them")
+	flag.BoolVar(&opts.analysis.GraphOnly, "graph-only", false, "print the graph without computing prime paths, for functions too large to enumerate them")
+	flag.BoolVar(&opts.analysis.EntryOnly, "entry-only", false, "only report paths that start at the function entry instead of prime paths")
+	flag.BoolVar(&opts.warnDead, "warn-dead", false, "report unreachable code on stderr and exit with status 2 if there is any")
+	flag.BoolVar(&opts.analysis.SimplePaths, "show-simple", false, "also list every simple path prime paths are chosen from")
+	flag.BoolVar(&opts.analysis.Cycles, "cycles", false, "also list every elementary cycle of the graph")
+	flag.BoolVar(&opts.comments, "comments", false, "show comments and labels in the CFG block dump")
+	snippet := flag.String("e", "", "analyze the Go `source` given: a file, declarations without a package clause, or a function body")
+	coverProfile := flag.String("coverprofile", "", "classify requirements by the blocks a go test -coverprofile `file` shows running")
+	flag.BoolVar(&opts.tours, "tours", false, "compute a test path touring each requirement")
+	flag.BoolVar(&opts.minimal, "minimal", false, "compute a minimal set of test paths touring all requirements")
+	sinks := flag.String("sinks", "", "comma-separated exit `kinds` test paths may end at: return, exit (calls to -no-return functions) and panic (with -panic-as-exit); all by default")
+	flag.BoolVar(&opts.cfg.PanicAsExit, "panic-as-exit", false, "treat calls to panic as never returning")
+	flag.BoolVar(&opts.cfg.SplitConditions, "split-conditions", false, "test each operand of && and || in its own block")
+	flag.BoolVar(&opts.cfg.SpliceDefers, "splice-defers", false, "run deferred calls in blocks of their own after each return")
+	noReturn := flag.String("no-return", "", "comma-separated `funcs` whose calls never return, e.g. log.Fatal,os.Exit")
+	funcs := flag.String("func", "", "comma-separated `names` of functions to analyze, e.g. f,T.Method")
+	flag.BoolVar(&opts.summary, "summary", false, "print only per-function metrics and a total")
+	flag.IntVar(&opts.analysis.Limits.MaxPaths, "max-paths", 0, "stop enumerating candidate prime paths after `N` paths (0 means no limit)")
+	flag.DurationVar(&opts.analysis.Limits.Timeout, "timeout", 0, "stop enumerating candidate prime paths of a function after `D` (0 means no limit)")
+	flag.BoolVar(&opts.genTests, "gen-tests", false, "print a _test.go skeleton with a subtest per requirement, or per minimal test path with -minimal")
+	flag.IntVar(&opts.verbose, "v", 2, "text output `level`: 0 prints requirements only, 1 adds graph info, 2 adds CFG blocks")
+	flag.IntVar(&opts.verbose, "verbose", 2, "same as -v")
+	flag.BoolVar(&opts.noColor, "no-color", false, "disable colored text output, which is on for terminals unless NO_COLOR is set")
+	flag.IntVar(&opts.maxPrimePaths, "max-prime-paths", 0, "exit with status 2 if a function has more than `N` prime paths (0 means no limit)")
+	flag.IntVar(&opts.maxComplexity, "max-complexity", 0, "exit with status 2 if a function's cyclomatic complexity exceeds `N` (0 means no limit)")
+	flag.BoolVar(&opts.flagInfeasible, "flag-infeasible", false, "mark requirements whose branch conditions obviously contradict each other")
+	flag.BoolVar(&opts.callGraph, "callgraph", false, "print the prime paths of each package's call graph instead of each function's CFG")
+	callInclude := flag.String("callgraph-include", "", "with -callgraph -packages, follow only calls into packages matching one of these comma-separated `globs`, including other packages; std matches the standard library")
+	callExclude := flag.String("callgraph-exclude", "", "with -callgraph -packages, leave out calls into packages matching one of these comma-separated `globs`, following calls into other packages otherwise")
+	sortBy := flag.String("sort", "", "order requirements: hot lists the ones -coverprofile counts run most often first")
+	flag.StringVar(&opts.criterion, "criterion", "prime", "coverage criterion: node, edge, edgepair or prime")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] [<go-file|dir|->...]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s diff [flags] <old.go> <new.go>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	opts.cfg.NoReturn = splitList(*noReturn)
+	opts.funcs = splitList(*funcs)
+	opts.tags = splitList(*tags)
+	opts.callInclude = splitList(*callInclude)
+	opts.callExclude = splitList(*callExclude)
+	if *coverProfile != "" {
+		profiles, err := cover.ParseProfiles(*coverProfile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
+		opts.profiles = profiles
+	}
 
-		fmt.Printf("=== Function: %s ===\n", fn.Name.Name)
+	if *sinks != "" {
+		var err error
+		if opts.sinks, err = parseSinks(splitList(*sinks)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if opts.analysis.Reverse {
+			fmt.Fprintln(os.Stderr, "Error: -sinks cannot be combined with -reverse")
+			os.Exit(1)
+		}
+	}
 
-		g := cfg.New(fn.Body, func(ce *ast.CallExpr) bool {
-			return false 
-		})
+	switch *sortBy {
+	case "":
+	case "hot":
+		if opts.profiles == nil {
+			fmt.Fprintln(os.Stderr, "Error: -sort hot requires -coverprofile")
+			os.Exit(1)
+		}
+		opts.sortHot = true
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown -sort order %q, want hot\n", *sortBy)
+		os.Exit(1)
+	}
 
-		fmt.Println("\nCFG Blocks:")
-		printCFG(g, fset)
+	if err := checkCriterion(opts.criterion); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-		graph, liveNodes := buildGraph(g)
+	if opts.analysis.EntryOnly && (opts.criterion != "prime" || opts.explain) {
+		fmt.Fprintln(os.Stderr, "Error: -entry-only requires -criterion prime and cannot be combined with -explain")
+		os.Exit(1)
+	}
 
-		printGraphInfo(graph, liveNodes)
+	if opts.analysis.GraphOnly && (opts.analysis.EntryOnly || opts.analysis.SimplePaths || opts.analysis.Cycles ||
+		opts.explain || opts.stats || opts.maxPrimePaths > 0 || opts.callGraph) {
+		fmt.Fprintln(os.Stderr, "Error: -graph-only cannot be combined with -entry-only, -show-simple, -cycles, -explain, -stats, -max-prime-paths or -callgraph")
+		os.Exit(1)
+	}
 
-		simplePaths := findAllSimplePaths(graph, liveNodes)
+	if *snippet != "" && (opts.callGraph || opts.packages) {
+		fmt.Fprintln(os.Stderr, "Error: -e cannot be combined with -callgraph or -packages")
+		os.Exit(1)
+	}
 
-		primePaths := filterPrimePaths(simplePaths)
+	if (opts.callInclude != nil || opts.callExclude != nil) && !(opts.callGraph && opts.packages) {
+		fmt.Fprintln(os.Stderr, "Error: -callgraph-include and -callgraph-exclude require -callgraph and -packages")
+		os.Exit(1)
+	}
+	for _, pattern := range slices.Concat(opts.callInclude, opts.callExclude) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: bad call graph pattern %q: %v\n", pattern, err)
+			os.Exit(1)
+		}
+	}
 
-		fmt.Println("\nPrime Paths:")
-		for i, path := range primePaths {
-			fmt.Printf("  %d: %v\n", i+1, path)
+	if opts.callGraph && (*format != "text" || opts.summary || opts.genTests) {
+		fmt.Fprintln(os.Stderr, "Error: -callgraph requires -format text and cannot be combined with -summary or -gen-tests")
+		os.Exit(1)
+	}
+
+	r := &runner{opts: opts}
+	var w io.Writer = os.Stdout
+	var outFile *os.File
+	if opts.quiet && *output != "" {
+		fmt.Fprintln(os.Stderr, "Error: -q cannot be combined with -o")
+		os.Exit(1)
+	} else if opts.quiet {
+		w = io.Discard
+	} else if *output != "" && isOutputDir(*output) && opts.callGraph {
+		fmt.Fprintln(os.Stderr, "Error: -callgraph writes a single output, -o cannot name a directory")
+		os.Exit(1)
+	} else if *output != "" && isOutputDir(*output) {
+		r.outDir, r.outExt = *output, outputExts[*format]
+		if opts.genTests {
+			r.outExt = "go"
+		}
+		r.newOut = func(w io.Writer) (formatter, error) {
+			return newFormatter(*format, w, opts)
 		}
-		fmt.Println()
+		w = io.Discard
+	} else if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		w, outFile = f, f
 	}
-}
 
-func printCFG(g *cfg.CFG, fset *token.FileSet) {
-	for _, block := range g.Blocks {
-		fmt.Printf("  Block %d", block.Index)
-		if block.Live {
-			fmt.Print(" (live)")
+	out, err := newFormatter(*format, w, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	r.out = out
+
+	args := flag.Args()
+	if len(args) == 0 && *snippet == "" {
+		args = []string{"-"}
+		if opts.packages {
+			args = []string{"."}
 		}
-		fmt.Println()
+	}
 
-		for _, node := range block.Nodes {
-			var buf bytes.Buffer
-			printer.Fprint(&buf, fset, node)
-			fmt.Printf("      %s\n", buf.String())
+	failed := false
+	if opts.callGraph {
+		if err := r.processCallGraph(w, args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+		}
+		args = nil
+	} else if opts.packages {
+		if err := r.processPackages(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+		}
+		args = nil
+	}
+	if *snippet != "" {
+		src, err := wrapSnippet(*snippet)
+		if err == nil {
+			err = r.processSource("snippet", src)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
+		}
+	}
+	for _, arg := range args {
+		files, generated, err := collectFiles(arg, opts.tests, opts.generated, buildContext(opts.tags))
+		r.generated += generated
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			failed = true
 		}
 
-		if len(block.Succs) > 0 {
-			fmt.Print("    -> ")
-			for i, succ := range block.Succs {
-				if i > 0 {
-					fmt.Print(", ")
-				}
-				fmt.Printf("Block %d", succ.Index)
+		for _, filename := range files {
+			if err := r.processFile(filename); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
 			}
-			fmt.Println()
 		}
 	}
-}
 
-func buildGraph(g *cfg.CFG) ([][]int, int) {
-	liveCount := 0
-	for _, block := range g.Blocks {
-		if block.Live {
-			liveCount++
+	if !r.matched && len(opts.funcs) > 0 {
+		fmt.Fprintf(os.Stderr, "No function matches -func %s; available functions:\n",
+			strings.Join(opts.funcs, ","))
+		for _, name := range r.available {
+			fmt.Fprintf(os.Stderr, "  %s\n", name)
 		}
 	}
 
-	graph := make([][]int, liveCount)
-	for i := range graph {
-		graph[i] = []int{}
+	if r.generated > 0 && !opts.quiet {
+		fmt.Fprintf(os.Stderr, "Skipped %d generated files\n", r.generated)
+	}
+	if r.trivial > 0 && !opts.quiet {
+		fmt.Fprintf(os.Stderr, "Skipped %d functions without branches\n", r.trivial)
 	}
 
-	for _, block := range g.Blocks {
-		if !block.Live {
-			continue
+	if err := out.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+		failed = true
+	}
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			failed = true
 		}
-		for _, succ := range block.Succs {
-			if succ.Live {
-				graph[block.Index] = append(graph[block.Index], int(succ.Index))
-			}
+		fmt.Fprintf(os.Stderr, "Wrote %s\n", *output)
+	}
+	if r.outDir != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d files to %s\n", len(r.written), r.outDir)
+	}
+	if failed {
+		os.Exit(1)
+	}
+	if len(r.over) > 0 {
+		fmt.Fprintln(os.Stderr, "Functions over threshold:")
+		for _, line := range r.over {
+			fmt.Fprintf(os.Stderr, "  %s\n", line)
 		}
+		os.Exit(2)
+	}
+	if r.dead > 0 {
+		fmt.Fprintf(os.Stderr, "Unreachable blocks: %d\n", r.dead)
+		os.Exit(2)
 	}
-
-	return graph, liveCount
 }
 
-func printGraphInfo(graph [][]int, n int) {
-	fmt.Println("\nGraph Info:")
+// runner analyzes files one at a time, accumulating state across them.
+type runner struct {
+	opts options
+	out  formatter
+
+	// matched records whether any function passed the -func filter, and
+	// available lists the functions seen, for reporting when none did.
+	matched   bool
+	available []string
+
+	// With -o naming a directory, outDir is that directory and each source
+	// file is written by a formatter from newOut to a file with extension
+	// outExt; written lists the files.
+	outDir  string
+	outExt  string
+	newOut  func(w io.Writer) (formatter, error)
+	written []string
+
+	// over lists the functions exceeding -max-prime-paths or
+	// -max-complexity.
+	over []string
+	// dead counts the unreachable blocks reported by -warn-dead.
+	dead int
+	// trivial counts the functions left out by -skip-trivial.
+	trivial int
+	// generated counts the generated files left out of directories.
+	generated int
+}
 
-	fmt.Println("Edges:")
-	for from := 0; from < n; from++ {
-		for _, to := range graph[from] {
-			fmt.Printf("  %d %d\n", from, to)
+func (r *runner) processFile(filename string) error {
+	if filename == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("stdin: %w", err)
 		}
+		return r.processSource("stdin", data)
 	}
+	return r.processSource(filename, nil)
+}
 
-	hasIncoming := make([]bool, n)
-	for from := 0; from < n; from++ {
-		for _, to := range graph[from] {
-			hasIncoming[to] = true
-		}
+// processSource analyzes the file filename, reading it from src if src is
+// not nil.
+func (r *runner) processSource(filename string, src any) error {
+	opts := r.opts
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+	var syntaxErrs scanner.ErrorList
+	if err != nil && (!errors.As(err, &syntaxErrs) || file == nil || file.Name == nil) {
+		return err
 	}
-	fmt.Print("Initial nodes: ")
-	first := true
-	for i := 0; i < n; i++ {
-		if !hasIncoming[i] {
-			if !first {
-				fmt.Print(", ")
-			}
-			fmt.Printf("%d", i)
-			first = false
+
+	// Analyze the functions that parsed cleanly and report the others.
+	for _, e := range syntaxErrs {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", e)
+	}
+	file.Decls = slices.DeleteFunc(file.Decls, func(decl ast.Decl) bool {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !hasSyntaxError(fset, fn, syntaxErrs) {
+			return false
 		}
+		r.warnf("%s:%s: skipped because of syntax errors\n", filename, primepath.FuncName(fn))
+		return true
+	})
+
+	err = r.withOutput(filename, func() error {
+		return r.analyzeFile(filename, fset, file, &opts.cfg)
+	})
+	if len(syntaxErrs) > 0 {
+		err = errors.Join(err, fmt.Errorf("%s: %d syntax errors", filename, len(syntaxErrs)))
 	}
-	fmt.Println()
+	return err
+}
 
-	fmt.Print("Final nodes: ")
-	first = true
-	for i := 0; i < n; i++ {
-		if len(graph[i]) == 0 {
-			if !first {
-				fmt.Print(", ")
-			}
-			fmt.Printf("%d", i)
-			first = false
+func hasSyntaxError(fset *token.FileSet, fn *ast.FuncDecl, errs scanner.ErrorList) bool {
+	start, end := fset.Position(fn.Pos()).Offset, fset.Position(fn.End()).Offset
+	if fn.Body == nil || !fn.Body.Rbrace.IsValid() {
+		// The body was cut short, so it runs to wherever parsing gave up.
+		end = math.MaxInt
+	}
+	for _, e := range errs {
+		if start <= e.Pos.Offset && e.Pos.Offset <= end {
+			return true
 		}
 	}
-	fmt.Println()
+	return false
 }
 
-func findAllSimplePaths(graph [][]int, n int) [][]int {
-	var allPaths [][]int
-
-	for start := 0; start < n; start++ {
-		visited := make([]bool, n)
-		path := []int{start}
-		findPathsDFS(graph, start, visited, path, &allPaths, start)
+func (r *runner) analyzeFile(filename string, fset *token.FileSet, file *ast.File, config *primepath.Config) error {
+	opts := r.opts
+	var comments ast.CommentMap
+	if opts.comments {
+		comments = ast.NewCommentMap(fset, file, file.Comments)
 	}
 
-	return allPaths
-}
+	var profile *cover.Profile
+	if opts.profiles != nil {
+		if profile = primepath.FindProfile(opts.profiles, filename); profile == nil {
+			r.warnf("%s: not in the coverage profile\n", filename)
+		}
+	}
 
-func findPathsDFS(graph [][]int, node int, visited []bool, path []int, allPaths *[][]int, startNode int) {
-	pathCopy := make([]int, len(path))
-	copy(pathCopy, path)
-	*allPaths = append(*allPaths, pathCopy)
+	var errs []error
+	for _, fn := range primepath.Funcs(file) {
+		if fn.Node == fn.Decl {
+			r.available = append(r.available, filename+":"+fn.Name)
+		}
+		decl, _, _ := strings.Cut(fn.Name, "$")
+		if !selected(decl, opts.funcs) {
+			continue
+		}
+		r.matched = true
 
-	visited[node] = true
+		result, err := config.AnalyzeFunc(fset, filename, file, fn, opts.analysis)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error building CFG: %v\n", err)
+			continue
+		}
+		if opts.skipTrivial && trivial(result) {
+			r.trivial++
+			continue
+		}
+		res := &funcResult{
+			Result:    *result,
+			Comments:  comments,
+			Criterion: opts.criterion,
+		}
+		res.TypeParams = typeParams(fset, fn)
+		if opts.signature {
+			res.Signature = funcSignature(fset, fn)
+		}
+		res.Requirements = requirements(opts.criterion, res.Graph, res.PrimePaths)
+		if entries := res.Entries(); len(entries) > 1 {
+			r.warnf("%s:%s: %d initial nodes (%s), a function should have a single entry\n",
+				filename, fn.Name, len(entries), joinInts(entries))
+		}
+		if res.Truncated {
+			r.warnf("%s:%s: truncated after %d candidate paths, prime paths are incomplete\n",
+				filename, fn.Name, len(res.Candidates))
+		}
+		r.checkThresholds(res)
+		if opts.warnDead {
+			for _, block := range res.Dead {
+				start, _ := primepath.BlockLines(fset, block)
+				fmt.Fprintf(os.Stderr, "Warning: %s:%d: unreachable code in %s %s\n",
+					filename, start, fn.Name, blockRange(fset, block))
+				r.dead++
+			}
+		}
+		if profile != nil {
+			counts := primepath.BlockCounts(fset, res.Blocks, profile)
+			for _, path := range res.Requirements {
+				res.Hotness = append(res.Hotness, primepath.PathHotness(res.ExpandPath(path), counts))
+			}
+			if opts.sortHot {
+				sortHot(res.Requirements, res.Hotness)
+			}
+			exec := primepath.BlockExecution(fset, res.Blocks, profile)
+			for _, path := range res.Requirements {
+				res.Coverage = append(res.Coverage, primepath.PathCoverage(res.ExpandPath(path), exec))
+			}
+		}
+		if opts.flagInfeasible {
+			res.Infeasible = make(map[int]string)
+			for i, path := range res.Requirements {
+				if reason, ok := res.Branches.Contradiction(fset, res.Blocks, res.ExpandPath(path)); ok {
+					res.Infeasible[i] = reason
+				}
+			}
+		}
+		if final := primepath.FinalNodes(res.Graph); opts.sinks != nil && len(final) > 0 {
+			res.Sinks = []int{}
+			for _, n := range final {
+				blocks := res.NodeBlocks(n)
+				if opts.sinks[config.ExitKind(blocks[len(blocks)-1])] {
+					res.Sinks = append(res.Sinks, n)
+				}
+			}
+		}
+		if opts.tours {
+			var feasible func([]int) bool
+			if opts.flagInfeasible {
+				feasible = func(path []int) bool {
+					_, ok := res.Branches.Contradiction(fset, res.Blocks, res.ExpandPath(path))
+					return !ok
+				}
+			}
+			res.Tours = primepath.FeasibleTours(res.Graph, res.Requirements, res.ends(), feasible)
+		}
+		if opts.minimal {
+			res.TestPaths = primepath.MinimalTestPathsEndingAt(res.Graph, res.Requirements, res.ends())
+		}
 
-	for _, next := range graph[node] {
-		if next == startNode && len(path) > 1 {
-			cyclePath := make([]int, len(path)+1)
-			copy(cyclePath, path)
-			cyclePath[len(path)] = next
-			*allPaths = append(*allPaths, cyclePath)
-		} else if !visited[next] {
-			path = append(path, next)
-			findPathsDFS(graph, next, visited, path, allPaths, startNode)
-			path = path[:len(path)-1]
+		err = r.out.Function(res)
+		if err != nil {
+			errs = append(errs, err)
 		}
 	}
-
-	visited[node] = false
+	return errors.Join(errs...)
 }
 
-func filterPrimePaths(paths [][]int) [][]int {
-	var primePaths [][]int
+// warnf reports a problem that does not stop the analysis, unless -q is
+// set.
+func (r *runner) warnf(format string, args ...any) {
+	if !r.opts.quiet {
+		fmt.Fprintf(os.Stderr, "Warning: "+format, args...)
+	}
+}
 
-	for _, path := range paths {
-		if isPrimePath(path, paths) {
-			primePaths = append(primePaths, path)
+// trivial reports whether the graph of r has neither branches nor loops,
+// so that a single path covers it. A loop without a branch, such as an
+// empty for {}, still has the loop and the path into it as prime paths. A
+// reversed graph is checked as built, since reversing turns its branches
+// into joins.
+func trivial(r *primepath.Result) bool {
+	graph := r.Graph
+	if r.Reversed {
+		graph = primepath.Reverse(graph)
+	}
+	for _, succs := range graph {
+		if len(succs) > 1 {
+			return false
 		}
 	}
+	return len(primepath.BackEdges(graph)) == 0
+}
 
-	return primePaths
+func (r *runner) checkThresholds(res *funcResult) {
+	complexity := res.Complexity()
+	if r.opts.maxPrimePaths > 0 && len(res.PrimePaths) > r.opts.maxPrimePaths ||
+		r.opts.maxComplexity > 0 && complexity > r.opts.maxComplexity {
+		r.over = append(r.over, fmt.Sprintf("%s:%s: prime=%d complexity=%d",
+			res.File, res.Func, len(res.PrimePaths), complexity))
+	}
 }
 
-func isPrimePath(path []int, allPaths [][]int) bool {
+// selected reports whether the function named name passes the -func
+// filter. Pointer receivers may be written without the star, so T.M
+// matches (*T).M, and init matches every init function as well as init#2
+// matching the second.
+func selected(name string, funcs []string) bool {
+	if len(funcs) == 0 {
+		return true
+	}
+	untyped := stripTypeParams(name)
+	bare := strings.NewReplacer("(*", "", ")", "").Replace(untyped)
+	unnumbered, _, _ := strings.Cut(name, "#")
+	for _, f := range funcs {
+		if f == name || f == untyped || f == bare || f == unnumbered {
+			return true
+		}
+	}
+	return false
+}
 
-	for _, other := range allPaths {
-		if len(other) > len(path) && isProperSubpath(path, other) {
-			return false
+// stripTypeParams drops the type parameters of a generic receiver from a
+// name such as "(*Stack[E]).Push".
+func stripTypeParams(name string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch {
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
 		}
 	}
-	return true
+	return b.String()
 }
 
-func isProperSubpath(sub, full []int) bool {
-	if len(sub) >= len(full) {
-		return false
+// sortHot sorts reqs by their hotness, hottest first, keeping hotness in
+// step. Ties keep their order.
+func sortHot(reqs [][]int, hotness []int) {
+	order := make([]int, len(reqs))
+	for i := range order {
+		order[i] = i
 	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(hotness[b], hotness[a])
+	})
+	sorted, hot := make([][]int, len(reqs)), make([]int, len(reqs))
+	for i, j := range order {
+		sorted[i], hot[i] = reqs[j], hotness[j]
+	}
+	copy(reqs, sorted)
+	copy(hotness, hot)
+}
 
-	for i := 0; i <= len(full)-len(sub); i++ {
-		match := true
-		for j := 0; j < len(sub); j++ {
-			if full[i+j] != sub[j] {
-				match = false
-				break
+// parseSinks parses the exit kinds given to -sinks.
+func parseSinks(names []string) (map[primepath.ExitKind]bool, error) {
+	sinks := make(map[primepath.ExitKind]bool)
+	for _, name := range names {
+		found := false
+		for _, kind := range []primepath.ExitKind{primepath.ReturnExit, primepath.NoReturnExit, primepath.PanicExit} {
+			if kind.String() == name {
+				sinks[kind], found = true, true
 			}
 		}
-		if match {
-			return true
+		if !found {
+			return nil, fmt.Errorf("unknown -sinks kind %q, want return, exit or panic", name)
 		}
 	}
-	return false
+	return sinks, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+// mermaidFormatter prints each graph as a fenced Mermaid flowchart, ready
+// to embed in Markdown.
+type mermaidFormatter struct {
+	w io.Writer
+}
+
+func (f *mermaidFormatter) Function(r *funcResult) error {
+	fmt.Fprintln(f.w, "```mermaid")
+	fmt.Fprintf(f.w, "---\ntitle: %s:%s\n---\n", r.File, r.label())
+	fmt.Fprintln(f.w, "flowchart TD")
+	fmt.Fprintln(f.w, "  classDef initial fill:#98fb98")
+	fmt.Fprintln(f.w, "  classDef final stroke-width:3px")
+
+	for n := range r.Graph {
+		label := fmt.Sprintf("%d %s", n, chainRange(r.Fset, r.NodeBlocks(n)))
+		fmt.Fprintf(f.w, "  n%d([\"%s\"])\n", n, strings.ReplaceAll(label, `"`, "#quot;"))
+	}
+	for from, succs := range r.Graph {
+		for _, to := range succs {
+			fmt.Fprintf(f.w, "  n%d --> n%d\n", from, to)
+		}
+	}
+	for _, n := range primepath.InitialNodes(r.Graph) {
+		fmt.Fprintf(f.w, "  class n%d initial\n", n)
+	}
+	for _, n := range primepath.FinalNodes(r.Graph) {
+		fmt.Fprintf(f.w, "  class n%d final\n", n)
+	}
+	_, err := fmt.Fprint(f.w, "```\n\n")
+	return err
+}
+
+func (f *mermaidFormatter) Close() error {
+	return nil
+}
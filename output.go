@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// outputExts maps formats to the extension of the files -o writes into a
+// directory.
+var outputExts = map[string]string{
+	"text":     "txt",
+	"json":     "json",
+	"jsonl":    "jsonl",
+	"dot":      "dot",
+	"edgelist": "txt",
+	"mermaid":  "md",
+	"csv":      "csv",
+}
+
+// isOutputDir reports whether -o names a directory: an existing one, or a
+// path ending in a separator.
+func isOutputDir(path string) bool {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// withOutput runs analyze with r.out writing to the output of filename.
+// Unless -o names a directory, r.out is shared by every file; otherwise
+// each source file gets its own formatter writing to
+// <dir>/<filename>.primepaths.<ext>, with filename as outputName gives it.
+func (r *runner) withOutput(filename string, analyze func() error) error {
+	if r.outDir == "" {
+		return analyze()
+	}
+
+	path := filepath.Join(r.outDir, outputName(filename)+".primepaths."+r.outExt)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	out, err := r.newOut(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	shared := r.out
+	r.out = out
+	err = analyze()
+	r.out = shared
+
+	err = errors.Join(err, out.Close(), f.Close())
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	r.written = append(r.written, path)
+	return nil
+}
+
+// outputName returns the path under the -o directory of the output for
+// filename, which must not lead out of it: filename relative to the working
+// directory if it lies inside, or else filename without its volume, root
+// and leading .. elements.
+func outputName(filename string) string {
+	if wd, err := os.Getwd(); err == nil && filepath.IsAbs(filename) {
+		if rel, err := filepath.Rel(wd, filename); err == nil && filepath.IsLocal(rel) {
+			return rel
+		}
+	}
+
+	name := filepath.ToSlash(filepath.Clean(filename))
+	name = strings.TrimPrefix(name, filepath.ToSlash(filepath.VolumeName(filename)))
+	elems := strings.Split(name, "/")
+	for len(elems) > 1 && (elems[0] == "" || elems[0] == "..") {
+		elems = elems[1:]
+	}
+	return filepath.FromSlash(strings.Join(elems, "/"))
+}
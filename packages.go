@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func (r *runner) processPackages(patterns []string) error {
+	config := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Tests: r.opts.tests,
+	}
+	if len(r.opts.tags) > 0 {
+		config.BuildFlags = []string{"-tags=" + strings.Join(r.opts.tags, ",")}
+	}
+	pkgs, err := packages.Load(config, patterns...)
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	seen := make(map[string]bool)
+	for _, pkg := range pkgs {
+		for _, perr := range pkg.Errors {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", perr)
+			failed = true
+		}
+		if isTestMain(pkg) {
+			continue
+		}
+
+		cfgConfig := r.opts.cfg
+		cfgConfig.Info = pkg.TypesInfo
+		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.Position(file.Pos()).Filename
+			// With -tests, files of a package also appear in its test
+			// variant.
+			if seen[filename] {
+				continue
+			}
+			seen[filename] = true
+
+			err := r.withOutput(filename, func() error {
+				return r.analyzeFile(filename, pkg.Fset, file, &cfgConfig)
+			})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
+			}
+		}
+	}
+	if failed {
+		return fmt.Errorf("errors while loading packages")
+	}
+	return nil
+}
+
+// isTestMain reports whether pkg is the test binary's main package, which
+// -tests loads along with the test variants and whose only file is
+// generated by go test.
+func isTestMain(pkg *packages.Package) bool {
+	return strings.HasSuffix(pkg.ID, ".test")
+}
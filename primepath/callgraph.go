@@ -0,0 +1,135 @@
+package primepath
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// CallGraph is a graph whose nodes are the functions declared in a set of
+// files and whose edges lead from a function to those it calls.
+type CallGraph struct {
+	// Funcs are the declared functions with bodies, in file order; node i
+	// of Graph is Funcs[i].
+	Funcs []*ast.FuncDecl
+	// External are the functions of other packages that BuildCallGraphFunc
+	// keeps calls to, in the order they are first called; node
+	// len(Funcs)+i of Graph is External[i], and has no successors.
+	External []*types.Func
+	// Names holds the name of each function, as given by UniqueNames, and
+	// then of each external function, as given by types.Func.FullName.
+	Names []string
+	Graph Graph
+}
+
+// BuildCallGraph builds the call graph of the functions declared in files,
+// which should make up one package. Calls made inside function literals
+// are attributed to the enclosing declaration, and calls to functions
+// declared elsewhere are left out.
+//
+// With info, calls are resolved to the functions they statically call, so
+// dynamic calls through interfaces and function values are left out too.
+// Without it, a call f() is taken to call the function f and a call x.M()
+// every method named M.
+func BuildCallGraph(files []*ast.File, info *types.Info) *CallGraph {
+	return buildCallGraph(files, info, nil)
+}
+
+// BuildCallGraphFunc is BuildCallGraph with calls resolved through info,
+// keeping only the calls to functions follow accepts but following them
+// into other packages too.
+func BuildCallGraphFunc(files []*ast.File, info *types.Info, follow func(*types.Func) bool) *CallGraph {
+	return buildCallGraph(files, info, follow)
+}
+
+func buildCallGraph(files []*ast.File, info *types.Info, follow func(*types.Func) bool) *CallGraph {
+	cg := &CallGraph{}
+	external := make(map[*types.Func]int)
+	byObj := make(map[types.Object]int)
+	byName := make(map[string][]int)
+	byMethod := make(map[string][]int)
+	names := make(UniqueNames)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			n := len(cg.Funcs)
+			cg.Funcs = append(cg.Funcs, fn)
+			cg.Names = append(cg.Names, names.Name(fn))
+			if info != nil {
+				if obj := info.Defs[fn.Name]; obj != nil {
+					byObj[obj] = n
+				}
+			}
+			if fn.Recv == nil {
+				byName[fn.Name.Name] = append(byName[fn.Name.Name], n)
+			} else {
+				byMethod[fn.Name.Name] = append(byMethod[fn.Name.Name], n)
+			}
+		}
+	}
+
+	cg.Graph = make(Graph, len(cg.Funcs))
+	for from, fn := range cg.Funcs {
+		succs := []int{}
+		ast.Inspect(fn.Body, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			var callees []int
+			if info != nil {
+				callee := typeutil.StaticCallee(info, call)
+				if callee == nil {
+					return true
+				}
+				callee = callee.Origin()
+				if follow != nil && !follow(callee) {
+					return true
+				}
+				if n, ok := byObj[callee]; ok {
+					callees = []int{n}
+				} else if follow != nil {
+					n, ok := external[callee]
+					if !ok {
+						n = len(cg.Funcs) + len(cg.External)
+						external[callee] = n
+						cg.External = append(cg.External, callee)
+						cg.Names = append(cg.Names, callee.FullName())
+					}
+					callees = []int{n}
+				}
+			} else {
+				fun := ast.Unparen(call.Fun)
+				switch index := fun.(type) {
+				case *ast.IndexExpr:
+					fun = index.X
+				case *ast.IndexListExpr:
+					fun = index.X
+				}
+				switch fun := fun.(type) {
+				case *ast.Ident:
+					callees = byName[fun.Name]
+				case *ast.SelectorExpr:
+					callees = byMethod[fun.Sel.Name]
+				}
+			}
+			for _, n := range callees {
+				if !slices.Contains(succs, n) {
+					succs = append(succs, n)
+				}
+			}
+			return true
+		})
+		slices.Sort(succs)
+		cg.Graph[from] = succs
+	}
+	for range cg.External {
+		cg.Graph = append(cg.Graph, []int{})
+	}
+	return cg
+}
@@ -0,0 +1,81 @@
+package primepath
+
+import (
+	"fmt"
+	"slices"
+)
+
+// IsPrime reports whether path is a prime path of graph. If it is not, the
+// reason says why, for example "no edge from 2 to 3", "not a simple path:
+// node 4 repeats" or "extendable at node 5: contained in [4 5 6]".
+//
+// A simple path is prime exactly when it cannot be extended by one node at
+// either end into a longer simple path or a simple cycle, so IsPrime checks
+// the neighbors of path's ends rather than enumerating every path.
+func IsPrime(path []int, graph [][]int) (bool, string) {
+	if reason := invalidPath(path, graph); reason != "" {
+		return false, reason
+	}
+	if isCycle(path) {
+		return true, ""
+	}
+	seen := nodeSet(path)
+
+	first, last := path[0], path[len(path)-1]
+	for _, next := range graph[last] {
+		if !seen[next] || next == first {
+			return false, fmt.Sprintf("extendable at node %d: contained in %v", last, append(slices.Clone(path), next))
+		}
+	}
+	for from, succs := range graph {
+		if slices.Contains(succs, first) && (!seen[from] || from == last) {
+			return false, fmt.Sprintf("extendable at node %d: contained in %v", first, append([]int{from}, path...))
+		}
+	}
+	return true, ""
+}
+
+// IsValidPath reports whether path is a simple path or simple cycle of
+// graph: a non-empty walk along its edges that repeats no node, except that
+// a cycle ends at the node it starts from.
+func IsValidPath(path []int, graph [][]int) bool {
+	return invalidPath(path, graph) == ""
+}
+
+// invalidPath returns why path is not a simple path or simple cycle of
+// graph, or "" if it is one.
+func invalidPath(path []int, graph [][]int) string {
+	if len(path) == 0 {
+		return "empty path"
+	}
+	for _, n := range path {
+		if n < 0 || n >= len(graph) {
+			return fmt.Sprintf("node %d is not in the graph", n)
+		}
+	}
+	for i := 1; i < len(path); i++ {
+		if !slices.Contains(graph[path[i-1]], path[i]) {
+			return fmt.Sprintf("no edge from %d to %d", path[i-1], path[i])
+		}
+	}
+	body := path
+	if isCycle(path) {
+		body = path[:len(path)-1]
+	}
+	seen := make(map[int]bool)
+	for _, n := range body {
+		if seen[n] {
+			return fmt.Sprintf("not a simple path: node %d repeats", n)
+		}
+		seen[n] = true
+	}
+	return ""
+}
+
+func nodeSet(nodes []int) map[int]bool {
+	set := make(map[int]bool, len(nodes))
+	for _, n := range nodes {
+		set[n] = true
+	}
+	return set
+}
@@ -0,0 +1,94 @@
+package primepath
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Collapse merges each chain of nodes of graph in which every node but the
+// last has a single successor and every node but the first a single
+// predecessor into one node. Collapsing drops no branch or join, so the
+// paths of the collapsed graph are those of graph with each chain taken as
+// a whole. It returns the collapsed graph and the nodes of graph each of
+// its nodes stands for, in chain order.
+func Collapse(graph Graph) (Graph, [][]int) {
+	preds := make([]int, len(graph))
+	for _, succs := range graph {
+		for _, to := range succs {
+			preds[to]++
+		}
+	}
+	// follows reports whether n continues the chain of its predecessor.
+	follows := func(from, n int) bool {
+		return len(graph[from]) == 1 && preds[n] == 1 && from != n
+	}
+	continues := make([]bool, len(graph))
+	for from, succs := range graph {
+		for _, to := range succs {
+			if follows(from, to) {
+				continues[to] = true
+			}
+		}
+	}
+
+	node := make([]int, len(graph))
+	for n := range node {
+		node[n] = -1
+	}
+	var chains [][]int
+	walk := func(head int) {
+		chain := []int{head}
+		node[head] = len(chains)
+		for n := head; len(graph[n]) == 1; {
+			next := graph[n][0]
+			if !follows(n, next) || node[next] >= 0 {
+				break
+			}
+			node[next] = len(chains)
+			chain = append(chain, next)
+			n = next
+		}
+		chains = append(chains, chain)
+	}
+	for n := range graph {
+		if !continues[n] {
+			walk(n)
+		}
+	}
+	// Whatever remains lies on cycles of continuing nodes only, such as an
+	// empty infinite loop; each is broken at its lowest node.
+	for n := range graph {
+		if node[n] < 0 {
+			walk(n)
+		}
+	}
+
+	// The second pass may start chains after those of higher nodes; order
+	// chains by first node so that the collapsed graph keeps the order of
+	// graph.
+	slices.SortFunc(chains, func(a, b []int) int { return cmp.Compare(a[0], b[0]) })
+	for i, chain := range chains {
+		for _, n := range chain {
+			node[n] = i
+		}
+	}
+
+	collapsed := make(Graph, len(chains))
+	for i, chain := range chains {
+		collapsed[i] = []int{}
+		for _, to := range graph[chain[len(chain)-1]] {
+			collapsed[i] = append(collapsed[i], node[to])
+		}
+	}
+	return collapsed, chains
+}
+
+// Expand maps path, a path over a graph collapsed by Collapse, back to the
+// nodes of the original graph.
+func Expand(path []int, chains [][]int) []int {
+	var expanded []int
+	for _, n := range path {
+		expanded = append(expanded, chains[n]...)
+	}
+	return expanded
+}
@@ -0,0 +1,194 @@
+package primepath
+
+import (
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// ConditionKind describes what a branch of the CFG tests.
+type ConditionKind int
+
+const (
+	// BoolCondition is an if or for condition, or a case of a tagless
+	// switch.
+	BoolCondition ConditionKind = iota
+	// CaseCondition compares a switch tag with a case expression.
+	CaseCondition
+	// TypeCondition tests the dynamic type of a type switch operand.
+	TypeCondition
+	// RangeCondition tests whether a range loop has another element.
+	RangeCondition
+	// CommCondition tests whether a select case is chosen.
+	CommCondition
+)
+
+// Condition is the condition that must hold, or fail, for control to take
+// a branch.
+type Condition struct {
+	Kind ConditionKind
+	// Node is the condition, case expression or type, range operand or
+	// communication clause.
+	Node ast.Node
+	// Tag is the switch tag for CaseCondition and the operand of the type
+	// switch for TypeCondition.
+	Tag ast.Node
+	// Value is the outcome of the test that takes the branch.
+	Value bool
+}
+
+// Format renders the condition and its required outcome, for example
+// "x > 0 is true" or "v is int is false".
+func (c Condition) Format(fset *token.FileSet) string {
+	switch c.Kind {
+	case CaseCondition:
+		return fmt.Sprintf("%s == %s is %t", exprString(fset, c.Tag), exprString(fset, c.Node), c.Value)
+	case TypeCondition:
+		return fmt.Sprintf("%s is %s is %t", exprString(fset, c.Tag), exprString(fset, c.Node), c.Value)
+	case RangeCondition:
+		if c.Value {
+			return "range " + exprString(fset, c.Node) + " has more elements"
+		}
+		return "range " + exprString(fset, c.Node) + " is exhausted"
+	case CommCondition:
+		if c.Value {
+			return "case " + exprString(fset, c.Node) + " is chosen"
+		}
+		return "case " + exprString(fset, c.Node) + " is not chosen"
+	}
+	return fmt.Sprintf("%s is %t", exprString(fset, c.Node), c.Value)
+}
+
+func exprString(fset *token.FileSet, node ast.Node) string {
+	var b strings.Builder
+	printer.Fprint(&b, fset, node)
+	return b.String()
+}
+
+// Branches resolves the conditions that select between the successors of
+// the blocks of a CFG.
+type Branches struct {
+	// Info, if non-nil, holds type information for the function, which
+	// Contradiction uses to tell integer operands and calls apart.
+	Info *types.Info
+
+	switches  map[*ast.CaseClause]ast.Stmt
+	typeIndex map[*cfg.Block]int
+	// addressed holds the variables that may change behind the back of
+	// the CFG: those whose address is taken or that closures assign.
+	addressed map[string]bool
+}
+
+// NewBranches prepares to resolve the branch conditions of g, the CFG of
+// body.
+func NewBranches(g *cfg.CFG, body *ast.BlockStmt) *Branches {
+	b := &Branches{
+		switches:  make(map[*ast.CaseClause]ast.Stmt),
+		typeIndex: make(map[*cfg.Block]int),
+		addressed: make(map[string]bool),
+	}
+	ast.Inspect(body, func(n ast.Node) bool {
+		var clauses []ast.Stmt
+		switch n := n.(type) {
+		case *ast.UnaryExpr:
+			if n.Op == token.AND {
+				for name := range idents(n.X) {
+					b.addressed[name] = true
+				}
+			}
+		case *ast.FuncLit:
+			for name := range writes(n.Body) {
+				b.addressed[name] = true
+			}
+		case *ast.SwitchStmt:
+			clauses = n.Body.List
+		case *ast.TypeSwitchStmt:
+			clauses = n.Body.List
+		}
+		for _, cc := range clauses {
+			b.switches[cc.(*ast.CaseClause)] = n.(ast.Stmt)
+		}
+		return true
+	})
+
+	// Each type in a multi-type case is tested in its own block; all but
+	// the first are KindSwitchNextCase blocks of the clause, created in
+	// order.
+	seen := make(map[ast.Stmt]int)
+	for _, block := range SortedBlocks(g) {
+		if block.Kind == cfg.KindSwitchNextCase {
+			seen[block.Stmt]++
+			b.typeIndex[block] = seen[block.Stmt]
+		}
+	}
+	return b
+}
+
+// Condition returns the condition under which control flows from the block
+// from to its successor to. It reports false when from does not branch.
+func (b *Branches) Condition(from, to *cfg.Block) (Condition, bool) {
+	if len(from.Succs) != 2 || (to != from.Succs[0] && to != from.Succs[1]) {
+		return Condition{}, false
+	}
+	value := to == from.Succs[0]
+	yes, no := from.Succs[0], from.Succs[1]
+
+	switch {
+	case from.Kind == cfg.KindRangeLoop:
+		rs := from.Stmt.(*ast.RangeStmt)
+		return Condition{Kind: RangeCondition, Node: rs.X, Value: value}, true
+
+	case yes.Kind == cfg.KindSelectCaseBody && no.Kind == cfg.KindSelectAfterCase:
+		cc := yes.Stmt.(*ast.CommClause)
+		return Condition{Kind: CommCondition, Node: cc.Comm, Value: value}, true
+
+	case no.Kind == cfg.KindSwitchNextCase:
+		cc := no.Stmt.(*ast.CaseClause)
+		if ts, ok := b.switches[cc].(*ast.TypeSwitchStmt); ok {
+			i := 0
+			if from.Kind == cfg.KindSwitchNextCase && from.Stmt == cc {
+				i = b.typeIndex[from]
+			}
+			if i >= len(cc.List) {
+				return Condition{}, false
+			}
+			return Condition{Kind: TypeCondition, Node: cc.List[i], Tag: typeSwitchOperand(ts), Value: value}, true
+		}
+		if len(from.Nodes) == 0 {
+			return Condition{}, false
+		}
+		cond := from.Nodes[len(from.Nodes)-1]
+		if sw, ok := b.switches[cc].(*ast.SwitchStmt); ok && sw.Tag != nil {
+			return Condition{Kind: CaseCondition, Node: cond, Tag: sw.Tag, Value: value}, true
+		}
+		return Condition{Kind: BoolCondition, Node: cond, Value: value}, true
+	}
+
+	if len(from.Nodes) == 0 {
+		return Condition{}, false
+	}
+	cond, ok := from.Nodes[len(from.Nodes)-1].(ast.Expr)
+	if !ok {
+		return Condition{}, false
+	}
+	return Condition{Kind: BoolCondition, Node: cond, Value: value}, true
+}
+
+func typeSwitchOperand(ts *ast.TypeSwitchStmt) ast.Node {
+	var x ast.Expr
+	switch s := ts.Assign.(type) {
+	case *ast.AssignStmt:
+		x = s.Rhs[0]
+	case *ast.ExprStmt:
+		x = s.X
+	}
+	if ta, ok := x.(*ast.TypeAssertExpr); ok {
+		return ta.X
+	}
+	return x
+}
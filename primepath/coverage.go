@@ -0,0 +1,152 @@
+package primepath
+
+import (
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/cover"
+	"golang.org/x/tools/go/cfg"
+)
+
+// Execution is what a coverage profile says about whether a block ran.
+type Execution int
+
+const (
+	// Unknown marks blocks without code the profile counts, such as
+	// empty blocks.
+	Unknown Execution = iota
+	NotExecuted
+	Executed
+)
+
+// Coverage classifies how much of a path a coverage profile shows running.
+type Coverage int
+
+const (
+	NotCovered Coverage = iota
+	PartiallyCovered
+	FullyCovered
+)
+
+func (c Coverage) String() string {
+	switch c {
+	case PartiallyCovered:
+		return "partially covered"
+	case FullyCovered:
+		return "covered"
+	}
+	return "not covered"
+}
+
+// FindProfile returns the profile of profiles whose file name, usually an
+// import path followed by a file name, shares the most trailing path
+// elements with filename, or nil if none shares even the file name.
+func FindProfile(profiles []*cover.Profile, filename string) *cover.Profile {
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		abs = filename
+	}
+	elems := strings.Split(filepath.ToSlash(abs), "/")
+
+	var best *cover.Profile
+	bestCount := 0
+	for _, p := range profiles {
+		pelems := strings.Split(p.FileName, "/")
+		n := 0
+		for n < len(elems) && n < len(pelems) && elems[len(elems)-1-n] == pelems[len(pelems)-1-n] {
+			n++
+		}
+		if n > bestCount {
+			best, bestCount = p, n
+		}
+	}
+	return best
+}
+
+// BlockExecution returns, for each of blocks, whether profile shows it
+// ran. A block ran if any of its statements lies in a profile block with a
+// non-zero count.
+func BlockExecution(fset *token.FileSet, blocks []*cfg.Block, profile *cover.Profile) []Execution {
+	exec := make([]Execution, len(blocks))
+	for i, block := range blocks {
+		for _, node := range block.Nodes {
+			start, end := fset.Position(node.Pos()), fset.Position(node.End())
+			for _, pb := range profile.Blocks {
+				// Skip profile blocks that do not overlap node.
+				if !less(start.Line, start.Column, pb.EndLine, pb.EndCol) ||
+					!less(pb.StartLine, pb.StartCol, end.Line, end.Column) {
+					continue
+				}
+				if pb.Count > 0 {
+					exec[i] = Executed
+				} else if exec[i] == Unknown {
+					exec[i] = NotExecuted
+				}
+			}
+		}
+	}
+	return exec
+}
+
+// BlockCounts returns, for each of blocks, how many times profile shows it
+// ran: the largest count of a profile block overlapping one of its
+// statements, or -1 for blocks without code the profile counts. Only
+// profiles written with -covermode count or atomic count beyond 1.
+func BlockCounts(fset *token.FileSet, blocks []*cfg.Block, profile *cover.Profile) []int {
+	counts := make([]int, len(blocks))
+	for i, block := range blocks {
+		counts[i] = -1
+		for _, node := range block.Nodes {
+			start, end := fset.Position(node.Pos()), fset.Position(node.End())
+			for _, pb := range profile.Blocks {
+				if less(start.Line, start.Column, pb.EndLine, pb.EndCol) &&
+					less(pb.StartLine, pb.StartCol, end.Line, end.Column) {
+					counts[i] = max(counts[i], pb.Count)
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// PathHotness estimates how often path, a path over nodes run counts
+// times, ran: at most as often as its least run node, ignoring nodes with
+// unknown counts, or 0 if every count is unknown.
+func PathHotness(path []int, counts []int) int {
+	hot := -1
+	for _, n := range path {
+		if c := counts[n]; c >= 0 && (hot < 0 || c < hot) {
+			hot = c
+		}
+	}
+	return max(hot, 0)
+}
+
+// less reports whether line1:col1 comes before line2:col2.
+func less(line1, col1, line2, col2 int) bool {
+	return line1 < line2 || line1 == line2 && col1 < col2
+}
+
+// PathCoverage classifies path, a path over nodes whose executions are
+// exec: fully covered if every node with a known execution ran, not
+// covered if none did. Execution of blocks does not imply the path ran in
+// that order, so this is only an approximation of path coverage.
+func PathCoverage(path []int, exec []Execution) Coverage {
+	ran, missed := 0, 0
+	for _, n := range path {
+		switch exec[n] {
+		case Executed:
+			ran++
+		case NotExecuted:
+			missed++
+		}
+	}
+	switch {
+	case ran == 0:
+		return NotCovered
+	case missed == 0:
+		return FullyCovered
+	}
+	return PartiallyCovered
+}
@@ -0,0 +1,65 @@
+package primepath
+
+import (
+	"cmp"
+	"slices"
+)
+
+// EdgeRequirements returns every directed edge of graph as an edge-coverage
+// test requirement, sorted by source and then target rather than in the
+// order of successors in the CFG.
+func EdgeRequirements(graph [][]int) [][2]int {
+	var edges [][2]int
+	for from, succs := range graph {
+		for _, to := range succs {
+			edges = append(edges, [2]int{from, to})
+		}
+	}
+	slices.SortFunc(edges, func(a, b [2]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]))
+	})
+	return edges
+}
+
+// NodeRequirements returns every node of graph as a node-coverage test
+// requirement.
+func NodeRequirements(graph [][]int) []int {
+	nodes := make([]int, len(graph))
+	for i := range graph {
+		nodes[i] = i
+	}
+	return nodes
+}
+
+// EdgePairRequirements returns every path of length two (three consecutive
+// nodes) in graph as an edge-pair-coverage test requirement, sorted by
+// node like EdgeRequirements.
+func EdgePairRequirements(graph [][]int) [][3]int {
+	var pairs [][3]int
+	for from, succs := range graph {
+		for _, mid := range succs {
+			for _, to := range graph[mid] {
+				pairs = append(pairs, [3]int{from, mid, to})
+			}
+		}
+	}
+	slices.SortFunc(pairs, func(a, b [3]int) int {
+		return cmp.Or(cmp.Compare(a[0], b[0]), cmp.Compare(a[1], b[1]), cmp.Compare(a[2], b[2]))
+	})
+	return pairs
+}
+
+// EdgeToPrimePaths maps each edge traversed by primePaths to the indices,
+// in ascending order, of the prime paths that traverse it.
+func EdgeToPrimePaths(primePaths [][]int) map[[2]int][]int {
+	index := make(map[[2]int][]int)
+	for i, path := range primePaths {
+		for j := 1; j < len(path); j++ {
+			edge := [2]int{path[j-1], path[j]}
+			if uses := index[edge]; len(uses) == 0 || uses[len(uses)-1] != i {
+				index[edge] = append(uses, i)
+			}
+		}
+	}
+	return index
+}
@@ -0,0 +1,15 @@
+package primepath
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEdgePairRequirementsSorted(t *testing.T) {
+	// The successors of nodes 0 and 2 are listed out of order.
+	graph := [][]int{{2, 1}, {3}, {3, 1}, {}}
+	want := [][3]int{{0, 1, 3}, {0, 2, 1}, {0, 2, 3}, {2, 1, 3}}
+	if got := EdgePairRequirements(graph); !slices.Equal(got, want) {
+		t.Errorf("EdgePairRequirements(%v) = %v, want %v", graph, got, want)
+	}
+}
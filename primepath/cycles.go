@@ -0,0 +1,67 @@
+package primepath
+
+import "slices"
+
+// Cycles returns every elementary cycle of graph, found with Johnson's
+// algorithm, as closed paths such as [2 4 5 2] in the canonical rotation
+// FilterPrimePaths uses and sorted lexicographically. Unlike the cycles
+// among the prime paths, none is left out for lying on a longer path.
+func Cycles(graph [][]int) [][]int {
+	var cycles [][]int
+	blocked := make([]bool, len(graph))
+	// blockedBy[n] holds the nodes to unblock once n is unblocked.
+	blockedBy := make([][]int, len(graph))
+	var stack []int
+
+	var unblock func(n int)
+	unblock = func(n int) {
+		blocked[n] = false
+		for _, m := range blockedBy[n] {
+			if blocked[m] {
+				unblock(m)
+			}
+		}
+		blockedBy[n] = blockedBy[n][:0]
+	}
+
+	// circuit searches for cycles through s, the smallest node of each, from
+	// n, and reports whether it found any.
+	var circuit func(s, n int) bool
+	circuit = func(s, n int) bool {
+		found := false
+		stack = append(stack, n)
+		blocked[n] = true
+		for _, m := range graph[n] {
+			switch {
+			case m < s:
+			case m == s:
+				cycles = append(cycles, append(slices.Clone(stack), s))
+				found = true
+			case !blocked[m] && circuit(s, m):
+				found = true
+			}
+		}
+		if found {
+			unblock(n)
+		} else {
+			for _, m := range graph[n] {
+				if m >= s && !slices.Contains(blockedBy[m], n) {
+					blockedBy[m] = append(blockedBy[m], n)
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		return found
+	}
+
+	for s := range graph {
+		for n := s; n < len(graph); n++ {
+			blocked[n] = false
+			blockedBy[n] = blockedBy[n][:0]
+		}
+		circuit(s, s)
+	}
+	slices.SortFunc(cycles, slices.Compare)
+	// Parallel edges find the same cycle twice.
+	return slices.CompactFunc(cycles, slices.Equal)
+}
@@ -0,0 +1,84 @@
+package primepath
+
+import (
+	"cmp"
+	"go/ast"
+	"slices"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// SpliceDefers rewrites g so that the calls deferred before a return run
+// after it. Each live block ending in a return statement is followed by a
+// chain of new blocks, one per defer statement whose block can reach the
+// return, holding the deferred call. The chain runs in reverse source
+// order, matching the last-in first-out order in which deferred calls run.
+//
+// The model is static: a deferred call appears once on every path to a
+// return its defer statement can reach, whether or not the defer ran on
+// that path and however often a loop ran it. Blocks that end in a call
+// that never returns get no chain.
+//
+// New blocks have Kind cfg.KindInvalid and are appended to g.Blocks.
+func SpliceDefers(g *cfg.CFG) {
+	var defers []*ast.DeferStmt
+	from := make(map[*ast.DeferStmt]*cfg.Block)
+	for _, block := range g.Blocks {
+		for _, n := range block.Nodes {
+			if d, ok := n.(*ast.DeferStmt); ok && block.Live {
+				defers = append(defers, d)
+				from[d] = block
+			}
+		}
+	}
+	if len(defers) == 0 {
+		return
+	}
+	slices.SortFunc(defers, func(a, b *ast.DeferStmt) int {
+		return cmp.Compare(b.Pos(), a.Pos())
+	})
+
+	reach := make(map[*cfg.Block]map[*cfg.Block]bool)
+	for _, d := range defers {
+		if b := from[d]; reach[b] == nil {
+			reach[b] = reachable(b)
+		}
+	}
+
+	for _, block := range g.Blocks {
+		if !block.Live || block.Return() == nil {
+			continue
+		}
+		last := block
+		for _, d := range defers {
+			if !reach[from[d]][block] {
+				continue
+			}
+			call := &cfg.Block{
+				Nodes: []ast.Node{d.Call},
+				Index: nextIndex(g),
+				Live:  true,
+			}
+			g.Blocks = append(g.Blocks, call)
+			last.Succs = []*cfg.Block{call}
+			last = call
+		}
+	}
+}
+
+// reachable returns the blocks reachable from block, including block.
+func reachable(block *cfg.Block) map[*cfg.Block]bool {
+	seen := map[*cfg.Block]bool{block: true}
+	queue := []*cfg.Block{block}
+	for len(queue) > 0 {
+		b := queue[0]
+		queue = queue[1:]
+		for _, succ := range b.Succs {
+			if !seen[succ] {
+				seen[succ] = true
+				queue = append(queue, succ)
+			}
+		}
+	}
+	return seen
+}
@@ -0,0 +1,80 @@
+package primepath
+
+import (
+	"cmp"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+func TestSpliceDefers(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "testdata", "defer.go"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		fn string
+		// chains lists the deferred calls run after each return, in
+		// the order the returns appear in the source.
+		chains [][]string
+	}{
+		// The first return comes before either defer statement.
+		{"cleanup", [][]string{nil, {`println("closing", name)`, "f.Close()"}, {`println("closing", name)`, "f.Close()"}}},
+		// Falling off the end is a return too.
+		{"closeAll", [][]string{{"f.Close()"}}},
+		// The return inside the loop can follow the defer of an earlier
+		// iteration.
+		{"openAll", [][]string{{"f.Close()"}, {"f.Close()"}}},
+		{"fallsOff", [][]string{nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fn, func(t *testing.T) {
+			i := slices.IndexFunc(file.Decls, func(decl ast.Decl) bool {
+				fn, ok := decl.(*ast.FuncDecl)
+				return ok && fn.Name.Name == tt.fn
+			})
+			if i < 0 {
+				t.Fatalf("no function named %s", tt.fn)
+			}
+			g, err := (&Config{SpliceDefers: true}).NewCFG(file.Decls[i].(*ast.FuncDecl))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var returns []*cfg.Block
+			for _, block := range g.Blocks {
+				if block.Live && block.Return() != nil {
+					returns = append(returns, block)
+				}
+			}
+			slices.SortFunc(returns, func(a, b *cfg.Block) int {
+				return cmp.Compare(a.Return().Pos(), b.Return().Pos())
+			})
+
+			var chains [][]string
+			for _, block := range returns {
+				var chain []string
+				for len(block.Succs) == 1 {
+					block = block.Succs[0]
+					if block.Kind != cfg.KindInvalid || len(block.Nodes) != 1 {
+						t.Fatalf("block %d after a return is not a deferred call", block.Index)
+					}
+					chain = append(chain, types.ExprString(block.Nodes[0].(ast.Expr)))
+				}
+				if len(block.Succs) > 0 {
+					t.Errorf("chain ends in block %d with successors", block.Index)
+				}
+				chains = append(chains, chain)
+			}
+			if !slices.EqualFunc(chains, tt.chains, slices.Equal) {
+				t.Errorf("deferred calls after each return = %q, want %q", chains, tt.chains)
+			}
+		})
+	}
+}
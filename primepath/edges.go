@@ -0,0 +1,132 @@
+package primepath
+
+import "slices"
+
+// EdgeKind classifies an edge by the depth-first search of ClassifyEdges.
+type EdgeKind int
+
+const (
+	// TreeEdge leads to a node the search reached first through it.
+	TreeEdge EdgeKind = iota
+	// ForwardEdge leads to a descendant already reached through another
+	// edge.
+	ForwardEdge
+	// BackEdge leads to an ancestor, or the node itself, closing a loop.
+	BackEdge
+	// CrossEdge leads to a node in a part of the search already finished.
+	CrossEdge
+)
+
+func (k EdgeKind) String() string {
+	switch k {
+	case ForwardEdge:
+		return "forward"
+	case BackEdge:
+		return "back"
+	case CrossEdge:
+		return "cross"
+	}
+	return "tree"
+}
+
+// ClassifyEdges classifies the edges of graph by a depth-first search from
+// its entry, then from any node the search has not reached, in ascending
+// order. kinds[n][i] is the kind of the edge from n to graph[n][i].
+func ClassifyEdges(graph [][]int) (kinds [][]EdgeKind) {
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make([]int, len(graph))
+	order := make([]int, len(graph))
+	visited := 0
+	kinds = make([][]EdgeKind, len(graph))
+
+	var visit func(node int)
+	visit = func(node int) {
+		state[node] = onStack
+		order[node] = visited
+		visited++
+		kinds[node] = make([]EdgeKind, len(graph[node]))
+		for i, next := range graph[node] {
+			switch {
+			case state[next] == unvisited:
+				visit(next)
+			case state[next] == onStack:
+				kinds[node][i] = BackEdge
+			case order[next] > order[node]:
+				kinds[node][i] = ForwardEdge
+			default:
+				kinds[node][i] = CrossEdge
+			}
+		}
+		state[node] = done
+	}
+	for _, n := range entryNodes(graph) {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+	for n := range graph {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+	return kinds
+}
+
+// BackEdges returns the back edges of graph found by ClassifyEdges, sorted
+// by source and then target.
+func BackEdges(graph [][]int) [][2]int {
+	var edges [][2]int
+	for from, kinds := range ClassifyEdges(graph) {
+		for i, kind := range kinds {
+			if kind == BackEdge {
+				edges = append(edges, [2]int{from, graph[from][i]})
+			}
+		}
+	}
+	slices.SortFunc(edges, func(a, b [2]int) int {
+		return slices.Compare(a[:], b[:])
+	})
+	return edges
+}
+
+// NestingDepth returns the largest number of loops of graph containing a
+// single node, 0 without loops. Each loop is the target of back edges and
+// the nodes that reach their sources without passing through it; loops
+// sharing a target count once.
+func NestingDepth(graph [][]int) int {
+	preds := Reverse(graph)
+	loops := make(map[int][]bool)
+	for _, edge := range BackEdges(graph) {
+		from, head := edge[0], edge[1]
+		body := loops[head]
+		if body == nil {
+			body = make([]bool, len(graph))
+			body[head] = true
+			loops[head] = body
+		}
+		stack := []int{from}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if body[n] {
+				continue
+			}
+			body[n] = true
+			stack = append(stack, preds[n]...)
+		}
+	}
+
+	depth := make([]int, len(graph))
+	for _, body := range loops {
+		for n, in := range body {
+			if in {
+				depth[n]++
+			}
+		}
+	}
+	return slices.Max(append(depth, 0))
+}
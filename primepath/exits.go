@@ -0,0 +1,66 @@
+package primepath
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// ExitKind classifies how a function leaves a final block.
+type ExitKind int
+
+const (
+	// ReturnExit returns, explicitly or by falling off the end of the body.
+	ReturnExit ExitKind = iota
+	// NoReturnExit calls a function listed in Config.NoReturn, such as
+	// os.Exit.
+	NoReturnExit
+	// PanicExit calls panic, with Config.PanicAsExit set.
+	PanicExit
+)
+
+func (k ExitKind) String() string {
+	switch k {
+	case NoReturnExit:
+		return "exit"
+	case PanicExit:
+		return "panic"
+	}
+	return "return"
+}
+
+// ExitKind classifies block, a block of a CFG built by c without
+// successors, by its last statement: a call that c says never returns ends
+// the function with NoReturnExit or PanicExit and anything else, including
+// the deferred calls SpliceDefers adds after a return, with ReturnExit.
+func (c *Config) ExitKind(block *cfg.Block) ExitKind {
+	if len(block.Nodes) == 0 {
+		return ReturnExit
+	}
+	stmt, ok := block.Nodes[len(block.Nodes)-1].(*ast.ExprStmt)
+	if !ok {
+		return ReturnExit
+	}
+	call, ok := stmt.X.(*ast.CallExpr)
+	if !ok || c.MayReturn(call) {
+		return ReturnExit
+	}
+	if c.isPanic(call) {
+		return PanicExit
+	}
+	return NoReturnExit
+}
+
+// isPanic reports whether call calls the builtin panic.
+func (c *Config) isPanic(call *ast.CallExpr) bool {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok || id.Name != "panic" {
+		return false
+	}
+	if c.Info == nil {
+		return true
+	}
+	_, builtin := c.Info.Uses[id].(*types.Builtin)
+	return builtin
+}
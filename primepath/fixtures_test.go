@@ -0,0 +1,311 @@
+package primepath
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// fixtures lists the expected paths of functions in the testdata directory
+// at the root of the module, analyzed with the zero Config and Options.
+var fixtures = []struct {
+	file, fn string
+	// graph, if not nil, is the expected graph.
+	graph Graph
+	prime [][]int
+	final []int
+}{
+	{
+		file: "nested.go", fn: "nested",
+		prime: [][]int{
+			{0, 3, 1, 7, 5, 8}, {0, 3, 1, 7, 6, 4}, {0, 3, 2},
+			{1, 7, 6, 4, 3, 1}, {1, 7, 6, 4, 3, 2}, {5, 8, 7, 5},
+			{5, 8, 7, 6, 4, 3, 1}, {5, 8, 7, 6, 4, 3, 2}, {6, 4, 3, 1, 7, 5, 8},
+		},
+		final: []int{2},
+	},
+	{
+		file: "nested.go", fn: "whileNested",
+		prime: [][]int{
+			{0, 3, 1, 6, 4}, {0, 3, 1, 6, 5}, {0, 3, 2},
+			{1, 6, 5, 3, 1}, {1, 6, 5, 3, 2}, {4, 6, 4},
+			{4, 6, 5, 3, 1}, {4, 6, 5, 3, 2}, {5, 3, 1, 6, 4},
+		},
+		final: []int{2},
+	},
+	{
+		file: "infinite.go", fn: "spin",
+		// No final node: spin never returns.
+		prime: [][]int{{0, 1}, {1, 1}},
+	},
+	{
+		file: "infinite.go", fn: "serve",
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {1, 3, 1}, {2, 1, 3}, {3, 1, 2}},
+	},
+	{
+		// continue outer leaves the inner loop for the outer loop head,
+		// node 2, and break outer for the return, node 4.
+		file: "labels.go", fn: "findPair",
+		graph: Graph{{1}, {2}, {3, 4}, {5}, {}, {6, 7}, {8, 9}, {2}, {2}, {10, 11}, {4}, {5}},
+		prime: [][]int{
+			{0, 1, 2, 3, 5, 6, 8}, {0, 1, 2, 3, 5, 6, 9, 10, 4}, {0, 1, 2, 3, 5, 6, 9, 11},
+			{0, 1, 2, 3, 5, 7}, {0, 1, 2, 4}, {2, 3, 5, 6, 8, 2}, {2, 3, 5, 7, 2},
+			{3, 5, 6, 8, 2, 4}, {3, 5, 7, 2, 4}, {5, 6, 9, 11, 5}, {6, 8, 2, 3, 5, 7},
+			{6, 9, 11, 5, 7, 2, 3}, {6, 9, 11, 5, 7, 2, 4}, {7, 2, 3, 5, 6, 8},
+			{7, 2, 3, 5, 6, 9, 10, 4}, {7, 2, 3, 5, 6, 9, 11}, {8, 2, 3, 5, 6, 9, 10, 4},
+			{8, 2, 3, 5, 6, 9, 11}, {9, 11, 5, 6, 8, 2, 3}, {9, 11, 5, 6, 8, 2, 4},
+			{11, 5, 6, 9, 10, 4},
+		},
+		final: []int{4},
+	},
+	{
+		// goto again jumps back from node 2 to the label, node 1.
+		file: "goto.go", fn: "retry",
+		graph: Graph{{1}, {2, 3}, {1}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}},
+		final: []int{3},
+	},
+	{
+		// goto done jumps ahead from node 1 to the label, node 3.
+		file: "goto.go", fn: "skipAhead",
+		graph: Graph{{1, 2}, {3}, {3}, {}},
+		prime: [][]int{{0, 1, 3}, {0, 2, 3}},
+		final: []int{3},
+	},
+	{
+		file: "goto.go", fn: "jumpOverDead",
+		graph: Graph{{1}, {}},
+		prime: [][]int{{0, 1}},
+		final: []int{1},
+	},
+	{
+		// Each of the three cases and the default is a branch of its own.
+		file: "select.go", fn: "poll",
+		graph: Graph{{1, 2}, {}, {3, 4}, {}, {5, 6}, {}, {}},
+		prime: [][]int{{0, 1}, {0, 2, 3}, {0, 2, 4, 5}, {0, 2, 4, 6}},
+		final: []int{1, 3, 5, 6},
+	},
+	{
+		// Without a default, node 5 is where the select blocks forever
+		// because neither case is chosen.
+		file: "select.go", fn: "wait",
+		graph: Graph{{2, 3}, {}, {}, {4, 5}, {1}, {}},
+		prime: [][]int{{0, 2}, {0, 3, 4, 1}, {0, 3, 5}},
+		final: []int{1, 2, 5},
+	},
+	{
+		// int and string are tested apart, at nodes 0 and 2, and share the
+		// body at node 1.
+		file: "typeswitch.go", fn: "kind",
+		graph: Graph{{1, 2}, {}, {1, 3}, {4, 5}, {}, {}},
+		prime: [][]int{{0, 1}, {0, 2, 1}, {0, 2, 3, 4}, {0, 2, 3, 5}},
+		final: []int{1, 4, 5},
+	},
+	{
+		// fallthrough is the edge 2 -> 3 into the next case body.
+		file: "fallthrough.go", fn: "grade",
+		graph: Graph{{2, 4}, {}, {3}, {1}, {3, 6}, {1}, {5}},
+		prime: [][]int{{0, 2, 3, 1}, {0, 4, 3, 1}, {0, 4, 6, 5, 1}},
+		final: []int{1},
+	},
+	{
+		// Every range loop has the body cycle 1 -> 2 -> 1 and the exit
+		// edge 1 -> 3.
+		file: "range.go", fn: "sumSlice",
+		graph: Graph{{1}, {2, 3}, {1}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}},
+		final: []int{3},
+	},
+	{
+		file: "range.go", fn: "sumMap",
+		graph: Graph{{1}, {2, 3}, {1}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}},
+		final: []int{3},
+	},
+	{
+		file: "range.go", fn: "drain",
+		graph: Graph{{1}, {2, 3}, {1}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}},
+		final: []int{3},
+	},
+	{
+		file: "range.go", fn: "count",
+		graph: Graph{{1}, {2, 3}, {1}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}},
+		final: []int{3},
+	},
+	{
+		file: "methods.go", fn: "Celsius.String",
+		prime: [][]int{{0, 1}, {0, 2}},
+		final: []int{1, 2},
+	},
+	{
+		file: "methods.go", fn: "(*Point).String",
+		prime: [][]int{{0, 1}, {0, 2}},
+		final: []int{1, 2},
+	},
+	{
+		// Falling off the end leaves node 2 without successors.
+		file: "defer.go", fn: "fallsOff",
+		graph: Graph{{1, 2}, {2}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 2}},
+		final: []int{2},
+	},
+	{
+		file: "defer.go", fn: "cleanup",
+		graph: Graph{{1, 2}, {}, {3, 4}, {}, {}},
+		prime: [][]int{{0, 1}, {0, 2, 3}, {0, 2, 4}},
+		final: []int{1, 3, 4},
+	},
+	{
+		file: "defer.go", fn: "closeAll",
+		prime: [][]int{
+			{0, 1, 2, 4}, {0, 1, 2, 5}, {0, 1, 3}, {1, 2, 4, 1}, {1, 2, 5, 1},
+			{2, 4, 1, 3}, {2, 5, 1, 3}, {4, 1, 2, 5}, {5, 1, 2, 4},
+		},
+		final: []int{3},
+	},
+	{
+		// A body without branches is a single block and prime path.
+		file: "trivial.go", fn: "empty",
+		graph: Graph{{}},
+		prime: [][]int{{0}},
+		final: []int{0},
+	},
+	{
+		file: "trivial.go", fn: "answer",
+		graph: Graph{{}},
+		prime: [][]int{{0}},
+		final: []int{0},
+	},
+	{
+		file: "trivial.go", fn: "declareOnly",
+		graph: Graph{{}},
+		prime: [][]int{{0}},
+		final: []int{0},
+	},
+	{
+		// The inner loop closes on node 7, not on the node a search
+		// starting at the entry begins from.
+		file: "innerloop.go", fn: "innerLoop",
+		graph: Graph{{3}, {7}, {}, {1, 2}, {3}, {7}, {4}, {5, 6}},
+		prime: [][]int{
+			{0, 3, 1, 7, 5}, {0, 3, 1, 7, 6, 4}, {0, 3, 2}, {1, 7, 6, 4, 3, 1},
+			{1, 7, 6, 4, 3, 2}, {5, 7, 5}, {5, 7, 6, 4, 3, 1}, {5, 7, 6, 4, 3, 2},
+			{6, 4, 3, 1, 7, 5},
+		},
+		final: []int{2},
+	},
+	{
+		// Each of the three returns is a final node of its own.
+		file: "returns.go", fn: "classify",
+		graph: Graph{{1, 2}, {}, {3, 4}, {}, {}},
+		prime: [][]int{{0, 1}, {0, 2, 3}, {0, 2, 4}},
+		final: []int{1, 3, 4},
+	},
+	{
+		// The deferred call does not join the returns.
+		file: "returns.go", fn: "classifyDeferred",
+		graph: Graph{{1, 2}, {}, {3, 4}, {}, {}},
+		prime: [][]int{{0, 1}, {0, 2, 3}, {0, 2, 4}},
+		final: []int{1, 3, 4},
+	},
+	{
+		file: "closures.go", fn: "pick",
+		graph: Graph{{1, 2}, {}, {}},
+		prime: [][]int{{0, 1}, {0, 2}},
+		final: []int{1, 2},
+	},
+	{
+		// Each literal on pick's line is analyzed as a function of its own.
+		file: "closures.go", fn: "pick$1",
+		graph: Graph{{}},
+		prime: [][]int{{0}},
+		final: []int{0},
+	},
+	{
+		file: "closures.go", fn: "pick$2",
+		graph: Graph{{}},
+		prime: [][]int{{0}},
+		final: []int{0},
+	},
+	{
+		file: "methods.go", fn: "(*Stack[E]).Push",
+		graph: Graph{{1, 2}, {2}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 2}},
+		final: []int{2},
+	},
+	{
+		file: "methods.go", fn: "Stack[E].Len",
+		prime: [][]int{{0, 1}, {0, 2}},
+		final: []int{1, 2},
+	},
+	{
+		file: "methods.go", fn: "(*Pair[K, V]).Swap",
+		graph: Graph{{1, 2}, {2}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 2}},
+		final: []int{2},
+	},
+	{
+		// A generic function has the paths of its instantiation.
+		file: "generics.go", fn: "Map",
+		graph: Graph{{1}, {2, 3}, {1}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}},
+		final: []int{3},
+	},
+	{
+		file: "generics.go", fn: "mapInts",
+		graph: Graph{{1}, {2, 3}, {1}, {}},
+		prime: [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}},
+		final: []int{3},
+	},
+	{
+		file: "generics.go", fn: "Max",
+		prime: [][]int{
+			{0, 1, 2, 4, 5}, {0, 1, 2, 5}, {0, 1, 3}, {1, 2, 4, 5, 1},
+			{1, 2, 5, 1}, {2, 4, 5, 1, 3}, {2, 5, 1, 3},
+		},
+		final: []int{3},
+	},
+	{
+		// The post statement, node 4, loops back to the condition, node 3,
+		// which exits to node 2.
+		file: "counted.go", fn: "count",
+		graph: Graph{{3}, {4}, {}, {1, 2}, {3}},
+		prime: [][]int{{0, 3, 1, 4}, {0, 3, 2}, {1, 4, 3, 1}, {1, 4, 3, 2}},
+		final: []int{2},
+	},
+}
+
+func TestFixtures(t *testing.T) {
+	analyzed := make(map[string][]Result)
+	for _, tt := range fixtures {
+		t.Run(tt.file+":"+tt.fn, func(t *testing.T) {
+			results, ok := analyzed[tt.file]
+			if !ok {
+				var err error
+				results, err = Analyze(filepath.Join("..", "testdata", tt.file))
+				if err != nil {
+					t.Fatal(err)
+				}
+				analyzed[tt.file] = results
+			}
+
+			i := slices.IndexFunc(results, func(r Result) bool { return r.Func == tt.fn })
+			if i < 0 {
+				t.Fatalf("no function named %s", tt.fn)
+			}
+			res := results[i]
+
+			if tt.graph != nil && !slices.EqualFunc(res.Graph, tt.graph, slices.Equal) {
+				t.Errorf("graph = %v, want %v", res.Graph, tt.graph)
+			}
+			if !slices.EqualFunc(res.PrimePaths, tt.prime, slices.Equal) {
+				t.Errorf("prime paths = %v, want %v", res.PrimePaths, tt.prime)
+			}
+			if final := FinalNodes(res.Graph); !slices.Equal(final, tt.final) {
+				t.Errorf("final nodes = %v, want %v", final, tt.final)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package primepath
+
+import (
+	"cmp"
+	"errors"
+	"go/ast"
+	"go/token"
+	"slices"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Graph is an adjacency list over the live blocks of a CFG.
+type Graph [][]int
+
+// BuildGraph converts the live blocks of g into an adjacency list. Live
+// blocks are renumbered densely in order of Index, so graph node i need not
+// be block i when g contains dead blocks; the returned blocks slice maps
+// each graph node back to its cfg.Block. A CFG without a live block, which
+// cfg.New never builds since the entry block is always live, is an error
+// rather than a graph without paths.
+func BuildGraph(g *cfg.CFG) (Graph, []*cfg.Block, error) {
+	if g == nil {
+		return nil, nil, errors.New("primepath: nil CFG")
+	}
+
+	var blocks []*cfg.Block
+	dense := make(map[*cfg.Block]int)
+	for _, block := range SortedBlocks(g) {
+		if block.Live {
+			dense[block] = len(blocks)
+			blocks = append(blocks, block)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil, nil, errors.New("primepath: CFG has no live blocks")
+	}
+
+	graph := make(Graph, len(dense))
+	for i := range graph {
+		graph[i] = []int{}
+	}
+
+	for from, block := range blocks {
+		for _, succ := range block.Succs {
+			if succ.Live {
+				graph[from] = append(graph[from], dense[succ])
+			}
+		}
+	}
+
+	return graph, blocks, nil
+}
+
+// DeadBlocks returns the blocks of g that are not live but hold
+// statements, that is the unreachable code of the function, ordered by
+// Index. Empty blocks, which the CFG creates after every jump, are left
+// out.
+func DeadBlocks(g *cfg.CFG) []*cfg.Block {
+	var dead []*cfg.Block
+	for _, block := range SortedBlocks(g) {
+		if !block.Live && len(block.Nodes) > 0 {
+			dead = append(dead, block)
+		}
+	}
+	return dead
+}
+
+// SortedBlocks returns the blocks of g ordered by Index, which need not be
+// their order in g.Blocks.
+func SortedBlocks(g *cfg.CFG) []*cfg.Block {
+	blocks := slices.Clone(g.Blocks)
+	slices.SortStableFunc(blocks, func(a, b *cfg.Block) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	return blocks
+}
+
+// nextIndex returns an Index for a block added to g that no block of g
+// has.
+func nextIndex(g *cfg.CFG) int32 {
+	var next int32
+	for _, block := range g.Blocks {
+		next = max(next, block.Index+1)
+	}
+	return next
+}
+
+// Reverse returns the transpose of graph, in which every edge points the
+// other way, so its initial nodes are the final nodes of graph and the
+// other way round.
+func Reverse(graph [][]int) [][]int {
+	reversed := make([][]int, len(graph))
+	for i := range reversed {
+		reversed[i] = []int{}
+	}
+	for from, succs := range graph {
+		for _, to := range succs {
+			reversed[to] = append(reversed[to], from)
+		}
+	}
+	return reversed
+}
+
+// InitialNodes returns the nodes of graph that have no incoming edges.
+func InitialNodes(graph [][]int) []int {
+	hasIncoming := make([]bool, len(graph))
+	for _, succs := range graph {
+		for _, to := range succs {
+			hasIncoming[to] = true
+		}
+	}
+
+	var nodes []int
+	for i := range graph {
+		if !hasIncoming[i] {
+			nodes = append(nodes, i)
+		}
+	}
+	return nodes
+}
+
+// FinalNodes returns the nodes of graph that have no outgoing edges.
+func FinalNodes(graph [][]int) []int {
+	var nodes []int
+	for i, succs := range graph {
+		if len(succs) == 0 {
+			nodes = append(nodes, i)
+		}
+	}
+	return nodes
+}
+
+// BlockLines returns the first and last source line spanned by the
+// statements of block, or zeros if the block has no statements.
+func BlockLines(fset *token.FileSet, block *cfg.Block) (start, end int) {
+	if len(block.Nodes) == 0 {
+		return 0, 0
+	}
+	last := block.Nodes[len(block.Nodes)-1]
+	start = fset.Position(block.Nodes[0].Pos()).Line
+	end = fset.Position(last.End()).Line
+	if ret, ok := last.(*ast.ReturnStmt); end == 0 || ok && ret.Results == nil {
+		// The implicit return synthesized at the closing brace ends
+		// past the brace, possibly past the end of the file.
+		end = fset.Position(last.Pos()).Line
+	}
+	return start, end
+}
+
+// LoopHeads returns the targets of the back edges found by ClassifyEdges,
+// in ascending order.
+func LoopHeads(graph [][]int) []int {
+	var heads []int
+	for _, edge := range BackEdges(graph) {
+		heads = append(heads, edge[1])
+	}
+	slices.Sort(heads)
+	return slices.Compact(heads)
+}
+
+// ExitNodes returns the nodes a complete test path may end at: the final
+// nodes of graph or, for a function that never returns, its loop heads.
+func ExitNodes(graph [][]int) []int {
+	if ends := FinalNodes(graph); len(ends) > 0 {
+		return ends
+	}
+	return LoopHeads(graph)
+}
+
+// entryNodes returns the initial nodes of graph, or node 0 when every node
+// has a predecessor.
+func entryNodes(graph [][]int) []int {
+	starts := InitialNodes(graph)
+	if len(starts) == 0 && len(graph) > 0 {
+		starts = []int{0}
+	}
+	return starts
+}
+
+// Complexity returns the cyclomatic complexity of graph. Every return is a
+// final node of its own, so the final nodes are joined into a single
+// virtual exit first, which gives E - N + F + 1 for F final nodes, or
+// E - N + 2 when there is none. graph must not be reversed, since its
+// initial and final nodes would trade places.
+func Complexity(graph [][]int) int {
+	edges := 0
+	for _, succs := range graph {
+		edges += len(succs)
+	}
+	return edges - len(graph) + max(1, len(FinalNodes(graph))) + 1
+}
@@ -0,0 +1,176 @@
+package primepath
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// parseFunc parses src, a file, and returns its first function.
+func parseFunc(t *testing.T, src string) *ast.FuncDecl {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "src.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			return fn
+		}
+	}
+	t.Fatal("no function in source")
+	return nil
+}
+
+const deadCodeSrc = `package p
+
+func f(x int) int {
+	if x > 0 {
+		return x
+		x++
+	}
+	for i := 0; i < x; i++ {
+		x--
+	}
+	return x
+}
+`
+
+func TestBuildGraphDeadBlocks(t *testing.T) {
+	g, err := NewCFG(parseFunc(t, deadCodeSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, blocks, err := BuildGraph(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Block 3, the x++ after the return, is dead, so the blocks after it
+	// move down a node.
+	want := Graph{{1, 2}, {}, {5}, {6}, {}, {3, 4}, {5}}
+	if !slices.EqualFunc(graph, want, slices.Equal) {
+		t.Errorf("graph = %v, want %v", graph, want)
+	}
+
+	var indices []int32
+	for _, block := range blocks {
+		indices = append(indices, block.Index)
+	}
+	if want := []int32{0, 1, 2, 4, 5, 6, 7}; !slices.Equal(indices, want) {
+		t.Errorf("block indices = %v, want %v", indices, want)
+	}
+	for _, block := range blocks {
+		if !block.Live {
+			t.Errorf("block %d is dead", block.Index)
+		}
+	}
+
+	if dead := DeadBlocks(g); len(dead) != 1 || dead[0].Index != 3 {
+		t.Errorf("DeadBlocks = %v, want block 3", dead)
+	}
+}
+
+func TestBuildGraphNoLiveBlocks(t *testing.T) {
+	g := &cfg.CFG{Blocks: []*cfg.Block{{Index: 0}, {Index: 1}}}
+	g.Blocks[0].Succs = []*cfg.Block{g.Blocks[1]}
+	if graph, blocks, err := BuildGraph(g); err == nil {
+		t.Errorf("BuildGraph = %v, %v, want an error", graph, blocks)
+	}
+
+	// A body holding only declarations still has a live entry block.
+	g, err := NewCFG(parseFunc(t, "package p\n\nfunc f() {\n\tvar x int\n\t_ = x\n}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph, _, err := BuildGraph(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Graph{{}}); !slices.EqualFunc(graph, want, slices.Equal) {
+		t.Errorf("graph = %v, want %v", graph, want)
+	}
+}
+
+func TestExitNodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph [][]int
+		want  []int
+	}{
+		{"returns", [][]int{{1, 2}, {}, {}}, []int{1, 2}},
+		// spin and serve in testdata/infinite.go never return, so tours
+		// end at their loop heads instead.
+		{"spin", [][]int{{1}, {1}}, []int{1}},
+		{"serve", [][]int{{1}, {2, 3}, {1}, {1}}, []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitNodes(tt.graph); !slices.Equal(got, tt.want) {
+				t.Errorf("ExitNodes(%v) = %v, want %v", tt.graph, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComplexity(t *testing.T) {
+	tests := []struct {
+		name  string
+		graph [][]int
+		want  int
+	}{
+		{"single block", [][]int{{}}, 1},
+		{"straight line", [][]int{{1}, {2}, {}}, 1},
+		{"if with join", [][]int{{1, 2}, {2}, {}}, 2},
+		// Celsius.String in testdata/methods.go returns from both branches.
+		{"if with two returns", [][]int{{1, 2}, {}, {}}, 2},
+		{"loop", [][]int{{1}, {2, 3}, {1}, {}}, 2},
+		// poll in testdata/select.go returns from each of four arms.
+		{"select", [][]int{{1, 2}, {}, {3, 4}, {}, {5, 6}, {}, {}}, 4},
+		// spin in testdata/infinite.go has no final node.
+		{"infinite loop", [][]int{{1}, {1}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Complexity(tt.graph); got != tt.want {
+				t.Errorf("Complexity(%v) = %d, want %d", tt.graph, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildGraphShuffledBlocks(t *testing.T) {
+	g, err := NewCFG(parseFunc(t, deadCodeSrc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantGraph, wantBlocks, err := BuildGraph(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	for range 10 {
+		rng.Shuffle(len(g.Blocks), func(i, j int) {
+			g.Blocks[i], g.Blocks[j] = g.Blocks[j], g.Blocks[i]
+		})
+		graph, blocks, err := BuildGraph(g)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.EqualFunc(graph, wantGraph, slices.Equal) {
+			t.Errorf("graph of shuffled blocks = %v, want %v", graph, wantGraph)
+		}
+		if !slices.Equal(blocks, wantBlocks) {
+			t.Errorf("blocks of shuffled blocks = %v, want %v", blocks, wantBlocks)
+		}
+		if dead := DeadBlocks(g); len(dead) != 1 || dead[0].Index != 3 {
+			t.Errorf("DeadBlocks of shuffled blocks = %v, want block 3", dead)
+		}
+	}
+}
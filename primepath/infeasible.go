@@ -0,0 +1,399 @@
+package primepath
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Contradiction reports whether path, a path over the graph whose nodes
+// map to blocks, requires branch conditions that cannot all hold, and if
+// so describes the conflict, for example "x < 0 is true contradicts x > 0
+// is true".
+//
+// It is a heuristic, not a feasibility analysis. It only compares
+// conditions on the same expression: the same test taken both ways, or
+// comparisons of one operand with constants that no value satisfies.
+// Operands are only taken to be integers, which no value lies strictly
+// between 0 and 1 of, when b.Info says so. Expressions that call functions
+// or receive from channels may change value between two tests, so they
+// are not compared. A condition is forgotten once a variable it mentions
+// is assigned on the path, and variables whose address is taken or that
+// function literals assign are ignored, but other writes made by calls go
+// unnoticed.
+func (b *Branches) Contradiction(fset *token.FileSet, blocks []*cfg.Block, path []int) (string, bool) {
+	var facts []fact
+	for i, n := range path {
+		block := blocks[n]
+		for _, node := range block.Nodes {
+			for name := range writes(node) {
+				facts = forget(facts, name)
+			}
+		}
+		if rs, ok := block.Stmt.(*ast.RangeStmt); ok && block.Kind == cfg.KindRangeLoop {
+			for _, x := range []ast.Expr{rs.Key, rs.Value} {
+				for name := range idents(x) {
+					facts = forget(facts, name)
+				}
+			}
+		}
+
+		if i+1 == len(path) {
+			break
+		}
+		cond, ok := b.Condition(block, blocks[path[i+1]])
+		if !ok {
+			continue
+		}
+		for _, f := range b.conditionFacts(cond) {
+			if b.mentionsAddressed(f) {
+				continue
+			}
+			if earlier, ok := conflict(facts, f); ok {
+				return cond.Format(fset) + " contradicts " + earlier.Format(fset), true
+			}
+			facts = append(facts, f)
+		}
+	}
+	return "", false
+}
+
+// fact is an outcome a path requires: that the expression key evaluates to
+// truth or, when op is set, that it compares to value as op says.
+type fact struct {
+	key    string
+	idents map[string]bool
+	truth  bool
+	op     token.Token
+	value  constant.Value
+	// integer reports that the expression is known to be an integer.
+	integer bool
+	cond    Condition
+}
+
+func (b *Branches) newFact(x ast.Expr, cond Condition) fact {
+	f := fact{key: types.ExprString(x), idents: idents(x), cond: cond}
+	if b.Info != nil {
+		if t := b.Info.TypeOf(x); t != nil {
+			basic, ok := t.Underlying().(*types.Basic)
+			f.integer = ok && basic.Info()&types.IsInteger != 0
+		}
+	}
+	return f
+}
+
+// conditionFacts breaks cond into facts. A true && and a false || require
+// each of their operands.
+func (b *Branches) conditionFacts(cond Condition) []fact {
+	switch cond.Kind {
+	case BoolCondition:
+		x, ok := cond.Node.(ast.Expr)
+		if !ok {
+			return nil
+		}
+		return b.exprFacts(x, cond.Value, cond)
+	case CaseCondition:
+		tag, ok := cond.Tag.(ast.Expr)
+		x, ok2 := cond.Node.(ast.Expr)
+		if !ok || !ok2 {
+			return nil
+		}
+		return b.exprFacts(&ast.BinaryExpr{X: tag, Op: token.EQL, Y: x}, cond.Value, cond)
+	}
+	return nil
+}
+
+func (b *Branches) exprFacts(x ast.Expr, truth bool, cond Condition) []fact {
+	x = ast.Unparen(x)
+	if u, ok := x.(*ast.UnaryExpr); ok && u.Op == token.NOT {
+		return b.exprFacts(u.X, !truth, cond)
+	}
+	if bin, ok := x.(*ast.BinaryExpr); ok {
+		switch {
+		case bin.Op == token.LAND && truth, bin.Op == token.LOR && !truth:
+			return append(b.exprFacts(bin.X, truth, cond), b.exprFacts(bin.Y, truth, cond)...)
+		case isComparison(bin.Op):
+			operand, op, value := bin.X, bin.Op, literalValue(bin.Y)
+			if value == nil {
+				operand, op, value = bin.Y, mirror(bin.Op), literalValue(bin.X)
+			}
+			if value != nil {
+				if b.volatile(operand) {
+					return nil
+				}
+				if !truth {
+					op = negate(op)
+				}
+				f := b.newFact(operand, cond)
+				f.op, f.value = op, value
+				return []fact{f}
+			}
+		}
+	}
+	if b.volatile(x) {
+		return nil
+	}
+	f := b.newFact(x, cond)
+	f.truth = truth
+	return []fact{f}
+}
+
+// volatile reports whether x calls a function or receives from a channel,
+// so that evaluating it twice may give different results. Conversions and
+// the builtins len and cap are not calls in this sense when b.Info can
+// tell them apart.
+func (b *Branches) volatile(x ast.Expr) bool {
+	found := false
+	ast.Inspect(x, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.UnaryExpr:
+			found = found || n.Op == token.ARROW
+		case *ast.CallExpr:
+			found = found || !b.pureCall(n)
+		}
+		return !found
+	})
+	return found
+}
+
+func (b *Branches) pureCall(call *ast.CallExpr) bool {
+	if b.Info == nil {
+		return false
+	}
+	if tv, ok := b.Info.Types[call.Fun]; ok && tv.IsType() {
+		return true
+	}
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	builtin, ok := b.Info.Uses[id].(*types.Builtin)
+	return ok && (builtin.Name() == "len" || builtin.Name() == "cap")
+}
+
+// conflict reports an earlier fact that f contradicts.
+func conflict(facts []fact, f fact) (Condition, bool) {
+	var same []fact
+	for _, e := range facts {
+		if e.key != f.key || (e.op == token.ILLEGAL) != (f.op == token.ILLEGAL) {
+			continue
+		}
+		if f.op == token.ILLEGAL {
+			if e.truth != f.truth {
+				return e.cond, true
+			}
+			continue
+		}
+		if sameKind(e.value, f.value) {
+			same = append(same, e)
+		}
+	}
+	if len(same) > 0 && !satisfiable(append(same, f)) {
+		return same[len(same)-1].cond, true
+	}
+	return Condition{}, false
+}
+
+// satisfiable reports whether some value compares to the constants of
+// facts as each of them requires.
+func satisfiable(facts []fact) bool {
+	var lo, hi, eq constant.Value
+	var loStrict, hiStrict bool
+	var ne []constant.Value
+	for _, f := range facts {
+		v, op := f.value, f.op
+		// Over the integers x > c is x >= c+1, and x < c is x <= c-1.
+		if f.integer && v.Kind() == constant.Int {
+			switch op {
+			case token.GTR:
+				v, op = constant.BinaryOp(v, token.ADD, constant.MakeInt64(1)), token.GEQ
+			case token.LSS:
+				v, op = constant.BinaryOp(v, token.SUB, constant.MakeInt64(1)), token.LEQ
+			}
+		}
+		switch op {
+		case token.EQL:
+			if eq != nil && !constant.Compare(eq, token.EQL, v) {
+				return false
+			}
+			eq = v
+		case token.NEQ:
+			ne = append(ne, v)
+		case token.GTR, token.GEQ:
+			if kind := v.Kind(); kind != constant.Int && kind != constant.Float && kind != constant.String {
+				return true
+			}
+			if lo == nil || constant.Compare(v, token.GTR, lo) || constant.Compare(v, token.EQL, lo) && op == token.GTR {
+				lo, loStrict = v, op == token.GTR
+			}
+		case token.LSS, token.LEQ:
+			if kind := v.Kind(); kind != constant.Int && kind != constant.Float && kind != constant.String {
+				return true
+			}
+			if hi == nil || constant.Compare(v, token.LSS, hi) || constant.Compare(v, token.EQL, hi) && op == token.LSS {
+				hi, hiStrict = v, op == token.LSS
+			}
+		}
+	}
+
+	if lo != nil && hi != nil {
+		if constant.Compare(lo, token.GTR, hi) || constant.Compare(lo, token.EQL, hi) && (loStrict || hiStrict) {
+			return false
+		}
+		if constant.Compare(lo, token.EQL, hi) && eq == nil {
+			eq = lo
+		}
+	}
+	if eq == nil {
+		return true
+	}
+	if lo != nil && (constant.Compare(eq, token.LSS, lo) || loStrict && constant.Compare(eq, token.EQL, lo)) {
+		return false
+	}
+	if hi != nil && (constant.Compare(eq, token.GTR, hi) || hiStrict && constant.Compare(eq, token.EQL, hi)) {
+		return false
+	}
+	for _, v := range ne {
+		if constant.Compare(eq, token.EQL, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func forget(facts []fact, name string) []fact {
+	kept := facts[:0]
+	for _, f := range facts {
+		if !f.idents[name] {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+func (b *Branches) mentionsAddressed(f fact) bool {
+	for name := range f.idents {
+		if b.addressed[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// writes returns the names of the variables node assigns, not counting
+// function literals.
+func writes(node ast.Node) map[string]bool {
+	names := make(map[string]bool)
+	add := func(x ast.Expr) {
+		for name := range idents(x) {
+			names[name] = true
+		}
+	}
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				add(lhs)
+			}
+		case *ast.IncDecStmt:
+			add(n.X)
+		case *ast.ValueSpec:
+			for _, name := range n.Names {
+				add(name)
+			}
+		}
+		return true
+	})
+	return names
+}
+
+func idents(x ast.Node) map[string]bool {
+	names := make(map[string]bool)
+	if x == nil {
+		return names
+	}
+	ast.Inspect(x, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			names[id.Name] = true
+		}
+		return true
+	})
+	return names
+}
+
+// literalValue returns the value of x if it is a literal constant, possibly
+// negated.
+func literalValue(x ast.Expr) constant.Value {
+	x = ast.Unparen(x)
+	if u, ok := x.(*ast.UnaryExpr); ok && u.Op == token.SUB {
+		if v := literalValue(u.X); v != nil {
+			return constant.UnaryOp(token.SUB, v, 0)
+		}
+		return nil
+	}
+	lit, ok := x.(*ast.BasicLit)
+	if !ok {
+		return nil
+	}
+	v := constant.MakeFromLiteral(lit.Value, lit.Kind, 0)
+	if v.Kind() == constant.Unknown {
+		return nil
+	}
+	return v
+}
+
+func sameKind(a, b constant.Value) bool {
+	numeric := func(v constant.Value) bool {
+		return v.Kind() == constant.Int || v.Kind() == constant.Float
+	}
+	return a.Kind() == b.Kind() || numeric(a) && numeric(b)
+}
+
+func isComparison(op token.Token) bool {
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return true
+	}
+	return false
+}
+
+// mirror returns the operator that gives the same result with its operands
+// swapped.
+func mirror(op token.Token) token.Token {
+	switch op {
+	case token.LSS:
+		return token.GTR
+	case token.LEQ:
+		return token.GEQ
+	case token.GTR:
+		return token.LSS
+	case token.GEQ:
+		return token.LEQ
+	}
+	return op
+}
+
+func negate(op token.Token) token.Token {
+	switch op {
+	case token.EQL:
+		return token.NEQ
+	case token.NEQ:
+		return token.EQL
+	case token.LSS:
+		return token.GEQ
+	case token.LEQ:
+		return token.GTR
+	case token.GTR:
+		return token.LEQ
+	case token.GEQ:
+		return token.LSS
+	}
+	return op
+}
@@ -0,0 +1,102 @@
+package primepath
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestContradiction(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// typed type-checks src and gives Contradiction the type info.
+		typed bool
+		// path is a path over the blocks of the first function of src.
+		path []int
+		want bool
+	}{
+		{
+			name: "opposite tests",
+			src:  "func f(x int) { if x > 0 { if x < 0 { println() } } }",
+			path: []int{0, 1, 3},
+			want: true,
+		},
+		{
+			name: "reassigned in between",
+			src:  "func f(x int) { if x > 0 { x = -x; if x < 0 { println() } } }",
+			path: []int{0, 1, 3},
+			want: false,
+		},
+		{
+			name:  "no integer between 0 and 1",
+			src:   "func f(x int) { if x > 0 { if x < 1 { println() } } }",
+			typed: true,
+			path:  []int{0, 1, 3},
+			want:  true,
+		},
+		{
+			name:  "fraction between 0 and 1",
+			src:   "func f(x float64) { if x > 0 { if x < 1 { println() } } }",
+			typed: true,
+			path:  []int{0, 1, 3},
+			want:  false,
+		},
+		{
+			name: "untyped operand between 0 and 1",
+			src:  "func f(x int) { if x > 0 { if x < 1 { println() } } }",
+			path: []int{0, 1, 3},
+			want: false,
+		},
+		{
+			name: "calls may differ",
+			src:  "func f(next func() int) { if next() > 5 { if next() < 3 { println() } } }",
+			path: []int{0, 1, 3},
+			want: false,
+		},
+		{
+			name: "receives may differ",
+			src:  "func f(c chan bool) { if <-c { if !<-c { println() } } }",
+			path: []int{0, 1, 3},
+			want: false,
+		},
+		{
+			name:  "conversions do not",
+			src:   "func f(x int) { if float64(x) > 5 { if float64(x) < 3 { println() } } }",
+			typed: true,
+			path:  []int{0, 1, 3},
+			want:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, "src.go", "package p\n\n"+tt.src, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var config Config
+			if tt.typed {
+				config.Info = &types.Info{
+					Types: make(map[ast.Expr]types.TypeAndValue),
+					Uses:  make(map[*ast.Ident]types.Object),
+				}
+				if _, err := new(types.Config).Check("p", fset, []*ast.File{file}, config.Info); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fn := Funcs(file)[0]
+			res, err := config.AnalyzeFunc(fset, "src.go", file, fn, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			reason, got := res.Branches.Contradiction(fset, res.Blocks, tt.path)
+			if got != tt.want {
+				t.Errorf("Contradiction(%v) = %q, %v, want %v", tt.path, reason, got, tt.want)
+			}
+		})
+	}
+}
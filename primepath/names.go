@@ -0,0 +1,103 @@
+package primepath
+
+import (
+	"fmt"
+	"go/ast"
+	"strings"
+)
+
+// Func is a function body to analyze: a declared function or method, or a
+// function literal nested inside one.
+type Func struct {
+	// Name is the name of the declaration as given by UniqueNames, with
+	// "$n" appended for its n-th function literal.
+	Name string
+	// Node is the *ast.FuncDecl or *ast.FuncLit.
+	Node ast.Node
+	Body *ast.BlockStmt
+	// Decl is the declaration Node is, or is nested in.
+	Decl *ast.FuncDecl
+}
+
+// Funcs returns every function with a body declared in file, each followed
+// by the function literals it contains. Literals are numbered in the order
+// they start in the source, nested ones after those enclosing them, which
+// depends on nothing but the file.
+func Funcs(file *ast.File) []Func {
+	var funcs []Func
+	names := make(UniqueNames)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		name := names.Name(fn)
+		funcs = append(funcs, Func{Name: name, Node: fn, Body: fn.Body, Decl: fn})
+
+		n := 0
+		ast.Inspect(fn.Body, func(node ast.Node) bool {
+			if lit, ok := node.(*ast.FuncLit); ok {
+				n++
+				funcs = append(funcs, Func{
+					Name: fmt.Sprintf("%s$%d", name, n),
+					Node: lit,
+					Body: lit.Body,
+					Decl: fn,
+				})
+			}
+			return true
+		})
+	}
+	return funcs
+}
+
+// UniqueNames tells apart functions that share a name, as several init
+// functions or functions named _ may: the n-th function given a name, for
+// n > 1, is named "init#2", "init#3" and so on. It counts the names given
+// so far.
+type UniqueNames map[string]int
+
+// Name returns FuncName of fn, with the ordinal appended if a function of
+// that name was seen before.
+func (u UniqueNames) Name(fn *ast.FuncDecl) string {
+	name := FuncName(fn)
+	u[name]++
+	if n := u[name]; n > 1 {
+		return fmt.Sprintf("%s#%d", name, n)
+	}
+	return name
+}
+
+// FuncName returns the qualified name of fn: "f" for functions, "T.M" for
+// methods with a value receiver and "(*T).M" for pointer receivers. The
+// type parameters of a generic receiver are kept, as in "(*Stack[E]).Push".
+func FuncName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := ast.Unparen(fn.Recv.List[0].Type)
+	if star, ok := recv.(*ast.StarExpr); ok {
+		return "(*" + recvTypeName(star.X) + ")." + fn.Name.Name
+	}
+	return recvTypeName(recv) + "." + fn.Name.Name
+}
+
+func recvTypeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.IndexExpr:
+		return recvTypeName(expr.X) + "[" + recvTypeName(expr.Index) + "]"
+	case *ast.IndexListExpr:
+		params := make([]string, len(expr.Indices))
+		for i, index := range expr.Indices {
+			params[i] = recvTypeName(index)
+		}
+		return recvTypeName(expr.X) + "[" + strings.Join(params, ", ") + "]"
+	case *ast.ParenExpr:
+		return recvTypeName(expr.X)
+	}
+	return "?"
+}
@@ -0,0 +1,74 @@
+package primepath
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFilePrimePathsMethods(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "testdata", "methods.go"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths, err := FilePrimePaths(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both methods are named String; the receiver tells them apart.
+	for _, name := range []string{"Celsius.String", "(*Point).String"} {
+		if _, ok := paths[name]; !ok {
+			t.Errorf("FilePrimePaths has no %s among %v", name, paths)
+		}
+	}
+}
+
+func TestFuncsLiteralsByColumn(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filepath.Join("..", "testdata", "closures.go"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// pick declares both literals on line 26; the one further left comes
+	// first.
+	want := []struct {
+		name   string
+		column int
+	}{{"pick$1", 16}, {"pick$2", 47}}
+	var got []Func
+	for _, fn := range Funcs(file) {
+		if fn.Decl.Name.Name == "pick" && fn.Node != fn.Decl {
+			got = append(got, fn)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("pick has %d function literals, want %d", len(got), len(want))
+	}
+	for i, fn := range got {
+		pos := fset.Position(fn.Node.Pos())
+		if fn.Name != want[i].name || pos.Line != 26 || pos.Column != want[i].column {
+			t.Errorf("literal %d is %s at %d:%d, want %s at 26:%d", i, fn.Name, pos.Line, pos.Column, want[i].name, want[i].column)
+		}
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), filepath.Join("..", "testdata", "methods.go"), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			got = append(got, FuncName(fn))
+		}
+	}
+	// Receivers are named as declared, with their type parameters.
+	want := []string{"Celsius.String", "(*Point).String", "(*Stack[E]).Push", "Stack[E].Len", "(*Pair[K, V]).Swap"}
+	if !slices.Equal(got, want) {
+		t.Errorf("FuncName = %q, want %q", got, want)
+	}
+}
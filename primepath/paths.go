@@ -0,0 +1,302 @@
+package primepath
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrTruncated is returned when path enumeration stops at a limit before
+// every path was found.
+var ErrTruncated = errors.New("primepath: path enumeration truncated")
+
+// Limits bounds simple path enumeration. The zero Limits enumerates every
+// path.
+type Limits struct {
+	// MaxPaths stops enumeration once this many paths have been found.
+	MaxPaths int
+	// Timeout stops enumeration once it has run this long.
+	Timeout time.Duration
+}
+
+// FindAllSimplePaths enumerates every simple path and simple cycle of graph.
+func FindAllSimplePaths(graph [][]int) [][]int {
+	return slices.Collect(AllSimplePaths(graph))
+}
+
+// AllSimplePaths yields the paths FindSimplePaths finds, in the same order,
+// as the search finds them, so that they need not all be held at once.
+// Each path is a new slice the caller may keep.
+func AllSimplePaths(graph [][]int) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		walkPaths(graph, false, nil, time.Time{}, yield)
+	}
+}
+
+// AllCandidatePaths yields the paths FindCandidatePaths finds, like
+// AllSimplePaths. Prime paths have no such iterator, since telling whether
+// a path is prime takes every candidate.
+func AllCandidatePaths(graph [][]int) iter.Seq[[]int] {
+	return func(yield func([]int) bool) {
+		walkPaths(graph, true, nil, time.Time{}, yield)
+	}
+}
+
+// FindSimplePaths enumerates the simple paths and simple cycles of graph
+// until limits is reached, in which case it returns the paths found so far
+// and ErrTruncated. A node that is its own successor forms the cycle
+// [n n].
+func FindSimplePaths(graph [][]int, limits Limits) ([][]int, error) {
+	return findPaths(graph, limits, false, nil)
+}
+
+// SimplePaths returns the simple paths and simple cycles of graph found by
+// FindSimplePaths, with each cycle once in the canonical rotation
+// FilterPrimePaths uses, sorted lexicographically by node sequence.
+func SimplePaths(graph [][]int, limits Limits) ([][]int, error) {
+	paths, err := FindSimplePaths(graph, limits)
+	var unique [][]int
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if isCycle(path) {
+			path = canonicalCycle(path)
+		}
+		if key := pathKey(path); !seen[key] {
+			seen[key] = true
+			unique = append(unique, path)
+		}
+	}
+	slices.SortFunc(unique, slices.Compare)
+	return unique, err
+}
+
+// FindCandidatePaths enumerates the simple cycles of graph and the simple
+// paths that cannot be extended at their end, stopping like
+// FindSimplePaths at limits. Every prime path is among them, since a path
+// that can be extended is a proper subpath of the longer one, so they can
+// take the place of all simple paths as the input of FilterPrimePaths
+// while leaving out the prefixes of every path.
+func FindCandidatePaths(graph [][]int, limits Limits) ([][]int, error) {
+	return findPaths(graph, limits, true, nil)
+}
+
+// FindEntryPaths is FindCandidatePaths restricted to paths that start at
+// the entry of graph: its initial nodes, or node 0 if every node has a
+// predecessor. Filtered by FilterPrimePaths, they give the longest simple
+// paths from the entry, which unlike the prime paths leave out loops that
+// do not start at the entry and the paths beginning inside them.
+func FindEntryPaths(graph [][]int, limits Limits) ([][]int, error) {
+	return findPaths(graph, limits, true, entryNodes(graph))
+}
+
+// findPaths collects the paths walkPaths finds until limits is reached.
+func findPaths(graph [][]int, limits Limits, maximal bool, starts []int) ([][]int, error) {
+	var deadline time.Time
+	if limits.Timeout > 0 {
+		deadline = time.Now().Add(limits.Timeout)
+	}
+	var allPaths [][]int
+	done := walkPaths(graph, maximal, starts, deadline, func(path []int) bool {
+		allPaths = append(allPaths, path)
+		return limits.MaxPaths <= 0 || len(allPaths) < limits.MaxPaths
+	})
+	if !done {
+		return allPaths, ErrTruncated
+	}
+	return allPaths, nil
+}
+
+// walkPaths enumerates simple paths by depth-first search from each node
+// of starts, or from every node if starts is nil, passing each to yield as
+// a new slice. With maximal, it yields a path only once no successor
+// extends it. It stops, returning false, when yield returns false or after
+// deadline, if not zero.
+func walkPaths(graph [][]int, maximal bool, starts []int, deadline time.Time, yield func([]int) bool) bool {
+	n := len(graph)
+
+	type frame struct {
+		node     int
+		next     int  // index into graph[node] of the next successor to try
+		extended bool // a successor extended the path through node
+	}
+
+	record := func(path []int) bool {
+		return yield(slices.Clone(path))
+	}
+	steps := 0
+
+	if starts == nil {
+		starts = make([]int, n)
+		for i := range starts {
+			starts[i] = i
+		}
+	}
+
+	visited := make([]bool, n)
+	for _, start := range starts {
+		path := []int{start}
+		stack := []frame{{node: start}}
+		visited[start] = true
+		if !maximal && !record(path) {
+			return false
+		}
+
+		for len(stack) > 0 {
+			steps++
+			if !deadline.IsZero() && steps%1024 == 0 && time.Now().After(deadline) {
+				return false
+			}
+
+			top := &stack[len(stack)-1]
+			succs := graph[top.node]
+			if top.next == len(succs) {
+				if maximal && !top.extended && !record(path) {
+					return false
+				}
+				visited[top.node] = false
+				stack = stack[:len(stack)-1]
+				path = path[:len(path)-1]
+				continue
+			}
+
+			next := succs[top.next]
+			top.next++
+			if next == start {
+				top.extended = true
+				if !record(append(path, next)) {
+					return false
+				}
+			} else if !visited[next] {
+				top.extended = true
+				visited[next] = true
+				path = append(path, next)
+				stack = append(stack, frame{node: next})
+				if !maximal && !record(path) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// FilterPrimePaths keeps the paths that are not a proper subpath of any
+// other path in paths. A prime path is a simple path, or a cycle whose only
+// repeated node is its first and last, that is not a proper subpath of any
+// other simple path.
+//
+// Rotations of a cycle, such as [2 4 5 2] and [4 5 2 4], describe the same
+// cyclic requirement, so each cycle is reported once in canonical form:
+// the rotation that starts at its smallest node. The result is sorted
+// lexicographically by node sequence.
+func FilterPrimePaths(paths [][]int) [][]int {
+	var primePaths [][]int
+
+	// Visit longer paths first: a path can only be a proper subpath of a
+	// longer one, and if it is contained in any path it is contained in a
+	// maximal one already kept. Duplicates fall out the same way.
+	sorted := slices.Clone(paths)
+	slices.SortStableFunc(sorted, func(a, b []int) int {
+		return len(b) - len(a)
+	})
+
+	covered := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, path := range sorted {
+		// A simple path containing a cycle must start and end at the
+		// cycle's node, so it is the cycle itself: cycles are always
+		// maximal.
+		if !isCycle(path) && covered[pathKey(path)] {
+			continue
+		}
+		markSubpaths(covered, path)
+
+		if isCycle(path) {
+			// Paths inside any rotation of the cycle are contained in it,
+			// even when that rotation is not among paths.
+			body := path[:len(path)-1]
+			for i := 1; i < len(body); i++ {
+				markSubpaths(covered, append(slices.Concat(body[i:], body[:i]), body[i]))
+			}
+			path = canonicalCycle(path)
+			key := pathKey(path)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		primePaths = append(primePaths, path)
+	}
+
+	slices.SortFunc(primePaths, slices.Compare)
+	return primePaths
+}
+
+// markSubpaths adds every subpath of path to covered. Whenever a subpath is
+// already present, so are all of its own subpaths, which bounds the work
+// for paths overlapping those marked before.
+func markSubpaths(covered map[string]bool, path []int) {
+	for i := 0; i < len(path); i++ {
+		if covered[pathKey(path[i:])] {
+			return
+		}
+		for j := len(path); j > i; j-- {
+			key := pathKey(path[i:j])
+			if covered[key] {
+				break
+			}
+			covered[key] = true
+		}
+	}
+}
+
+func pathKey(path []int) string {
+	var b strings.Builder
+	for i, n := range path {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strconv.Itoa(n))
+	}
+	return b.String()
+}
+
+func containsSubpath(full, sub []int) bool {
+	for i := 0; i <= len(full)-len(sub); i++ {
+		match := true
+		for j := 0; j < len(sub); j++ {
+			if full[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func isCycle(path []int) bool {
+	return len(path) > 1 && path[0] == path[len(path)-1]
+}
+
+// canonicalCycle rotates cycle so that it starts and ends at its smallest
+// node.
+func canonicalCycle(cycle []int) []int {
+	body := cycle[:len(cycle)-1]
+	start := 0
+	for i, n := range body {
+		if n < body[start] {
+			start = i
+		}
+	}
+
+	rotated := make([]int, 0, len(cycle))
+	rotated = append(rotated, body[start:]...)
+	rotated = append(rotated, body[:start]...)
+	return append(rotated, rotated[0])
+}
@@ -0,0 +1,188 @@
+package primepath
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFilterPrimePathsKeepsCycles(t *testing.T) {
+	// An outer loop 1 -> 2 -> 1 around an inner loop 2 -> 3 -> 2.
+	graph := [][]int{{1}, {2, 4}, {3, 1}, {2}, {}}
+	candidates, err := FindCandidatePaths(graph, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Neither cycle is contained in a longer simple path, so both are
+	// prime.
+	want := [][]int{{0, 1, 2, 3}, {0, 1, 4}, {1, 2, 1}, {2, 3, 2}, {3, 2, 1, 4}}
+	if got := FilterPrimePaths(candidates); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("FilterPrimePaths = %v, want %v", got, want)
+	}
+}
+
+func TestFilterPrimePathsRotations(t *testing.T) {
+	tests := []struct {
+		name  string
+		paths [][]int
+		want  [][]int
+	}{
+		{
+			name:  "rotations",
+			paths: [][]int{{2, 4, 5, 2}, {4, 5, 2, 4}, {5, 2, 4, 5}},
+			want:  [][]int{{2, 4, 5, 2}},
+		},
+		{
+			name:  "rotated to smallest node",
+			paths: [][]int{{5, 2, 4, 5}},
+			want:  [][]int{{2, 4, 5, 2}},
+		},
+		{
+			name:  "distinct cycles over the same nodes",
+			paths: [][]int{{2, 4, 5, 2}, {2, 5, 4, 2}},
+			want:  [][]int{{2, 4, 5, 2}, {2, 5, 4, 2}},
+		},
+		{
+			name:  "paths inside a rotation",
+			paths: [][]int{{4, 5, 2, 4}, {5, 2, 4}, {2, 4, 5}},
+			want:  [][]int{{2, 4, 5, 2}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FilterPrimePaths(tt.paths); !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("FilterPrimePaths(%v) = %v, want %v", tt.paths, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterPrimePathsSorted(t *testing.T) {
+	// Successors are listed in reverse, so the search finds the paths
+	// through 3 before those through 1.
+	graph := [][]int{{3, 1}, {2}, {4, 1}, {2}, {}}
+	candidates, err := FindCandidatePaths(graph, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]int{{0, 1, 2, 4}, {0, 3, 2, 1}, {0, 3, 2, 4}, {1, 2, 1}}
+	if got := FilterPrimePaths(candidates); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("FilterPrimePaths = %v, want %v", got, want)
+	}
+	slices.Reverse(candidates)
+	if got := FilterPrimePaths(candidates); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("FilterPrimePaths of reversed candidates = %v, want %v", got, want)
+	}
+}
+
+func TestPrimePathsInnerCycle(t *testing.T) {
+	// 1 -> 2 -> 3 -> 2: the cycle closes on 2, not on the entry.
+	graph := [][]int{{1}, {2}, {3}, {2, 4}, {}}
+	candidates, err := FindCandidatePaths(graph, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int{{0, 1, 2, 3, 4}, {2, 3, 2}}
+	if got := FilterPrimePaths(candidates); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("FilterPrimePaths = %v, want %v", got, want)
+	}
+}
+
+func TestPrimePathsSelfLoop(t *testing.T) {
+	// Node 1 is its own successor.
+	graph := [][]int{{1}, {1, 2}, {}}
+	candidates, err := FindCandidatePaths(graph, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]int{{0, 1, 2}, {1, 1}}
+	if got := FilterPrimePaths(candidates); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("FilterPrimePaths = %v, want %v", got, want)
+	}
+
+	simple, err := SimplePaths(graph, Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = [][]int{{0}, {0, 1}, {0, 1, 2}, {1}, {1, 1}, {1, 2}, {2}}
+	if !slices.EqualFunc(simple, want, slices.Equal) {
+		t.Errorf("SimplePaths = %v, want %v", simple, want)
+	}
+}
+
+// severalLoops is the graph of findPair in testdata/labels.go, two nested
+// loops left by labeled break and continue.
+var severalLoops = [][]int{
+	{1}, {2}, {3, 4}, {5}, {}, {6, 7}, {8, 9}, {2}, {2}, {10, 11}, {4}, {5},
+}
+
+// BenchmarkFilterPrimePaths filters every simple path of severalLoops, as
+// found with duplicates, and once deduplicated.
+func BenchmarkFilterPrimePaths(b *testing.B) {
+	all, err := FindSimplePaths(severalLoops, Limits{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	unique, err := SimplePaths(severalLoops, Limits{})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("all", func(b *testing.B) {
+		for b.Loop() {
+			FilterPrimePaths(all)
+		}
+	})
+	b.Run("deduplicated", func(b *testing.B) {
+		for b.Loop() {
+			FilterPrimePaths(unique)
+		}
+	})
+}
+
+// diamondLoop returns a loop around k if-else diamonds in a row, entered
+// at node 0 and left at the last node.
+func diamondLoop(k int) [][]int {
+	graph := [][]int{{1}}
+	for range k {
+		n := len(graph)
+		graph = append(graph, []int{n + 1, n + 2}, []int{n + 3}, []int{n + 3})
+	}
+	return append(graph, []int{1, len(graph) + 1}, []int{})
+}
+
+// BenchmarkFilterPrimePathsSynthetic filters 200 of the simple paths of a
+// loop around three diamonds, which has 345 of them.
+func BenchmarkFilterPrimePathsSynthetic(b *testing.B) {
+	paths, err := FindSimplePaths(diamondLoop(3), Limits{MaxPaths: 200})
+	if len(paths) != 200 {
+		b.Fatalf("found %d paths (%v), want 200", len(paths), err)
+	}
+	for b.Loop() {
+		FilterPrimePaths(paths)
+	}
+}
+
+// BenchmarkPrimePaths computes the prime paths of a loop around four
+// diamonds from every simple path, and from the candidates that cannot be
+// extended at their end.
+func BenchmarkPrimePaths(b *testing.B) {
+	graph := diamondLoop(4)
+	for _, bm := range []struct {
+		name string
+		find func([][]int, Limits) ([][]int, error)
+	}{
+		{"simple", FindSimplePaths},
+		{"candidates", FindCandidatePaths},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			for b.Loop() {
+				paths, err := bm.find(graph, Limits{})
+				if err != nil {
+					b.Fatal(err)
+				}
+				FilterPrimePaths(paths)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package primepath computes prime paths over the control-flow graph of Go
+// functions.
+package primepath
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/cfg"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// Config controls how control-flow graphs are built. The zero Config
+// assumes every call returns normally.
+type Config struct {
+	// PanicAsExit treats calls to the builtin panic as never returning, so
+	// code following them is unreachable.
+	PanicAsExit bool
+
+	// NoReturn lists functions, written as they are called (for example
+	// "log.Fatal", "os.Exit" or "t.FailNow"), whose calls never return.
+	// When Info is set, a function's full name as given by
+	// types.Func.FullName, such as "(*testing.common).FailNow", matches
+	// too.
+	NoReturn []string
+
+	// Info, if non-nil, holds type information for the code being built,
+	// used to resolve callees instead of relying on how they are spelled.
+	Info *types.Info
+
+	// SplitConditions tests each operand of a short-circuit && or || in
+	// its own block, as described by SplitConditions.
+	SplitConditions bool
+
+	// SpliceDefers runs deferred calls after each return, as described by
+	// SpliceDefers.
+	SpliceDefers bool
+}
+
+// NewCFG builds the control-flow graph of fn's body using the zero Config.
+func NewCFG(fn *ast.FuncDecl) (*cfg.CFG, error) {
+	return (&Config{}).NewCFG(fn)
+}
+
+// NewCFG builds the control-flow graph of fn's body.
+func (c *Config) NewCFG(fn *ast.FuncDecl) (*cfg.CFG, error) {
+	if fn == nil {
+		return nil, errors.New("primepath: nil function")
+	}
+	return c.BodyCFG(fn.Body)
+}
+
+// BodyCFG builds the control-flow graph of a function body.
+func (c *Config) BodyCFG(body *ast.BlockStmt) (*cfg.CFG, error) {
+	if body == nil {
+		return nil, errors.New("primepath: function has no body")
+	}
+	g := cfg.New(body, c.MayReturn)
+	if c.SplitConditions {
+		SplitConditions(g, body)
+	}
+	if c.SpliceDefers {
+		SpliceDefers(g)
+	}
+	return g, nil
+}
+
+// MayReturn reports whether call may return normally.
+func (c *Config) MayReturn(call *ast.CallExpr) bool {
+	var fullName string
+	if c.Info != nil {
+		switch callee := typeutil.Callee(c.Info, call).(type) {
+		case *types.Builtin:
+			return !(c.PanicAsExit && callee.Name() == "panic")
+		case *types.Func:
+			fullName = callee.FullName()
+		}
+	} else if c.PanicAsExit {
+		if id, ok := call.Fun.(*ast.Ident); ok && id.Name == "panic" {
+			return false
+		}
+	}
+
+	name := callName(call.Fun)
+	for _, noReturn := range c.NoReturn {
+		if noReturn == name && name != "" || noReturn == fullName && fullName != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// callName renders the dotted name a call is made through, or "" when fun
+// is not an identifier or a chain of selectors on one.
+func callName(fun ast.Expr) string {
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		if x := callName(fun.X); x != "" {
+			return x + "." + fun.Sel.Name
+		}
+	case *ast.ParenExpr:
+		return callName(fun.X)
+	}
+	return ""
+}
+
+// PrimePaths returns the prime paths of fn's control-flow graph.
+func PrimePaths(fn *ast.FuncDecl) ([][]int, error) {
+	if fn == nil {
+		return nil, errors.New("primepath: nil function")
+	}
+	return bodyPrimePaths(fn.Body)
+}
+
+func bodyPrimePaths(body *ast.BlockStmt) ([][]int, error) {
+	g, err := (&Config{}).BodyCFG(body)
+	if err != nil {
+		return nil, err
+	}
+
+	graph, _, err := BuildGraph(g)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, _ := FindCandidatePaths(graph, Limits{})
+	return FilterPrimePaths(candidates), nil
+}
+
+// FilePrimePaths returns the prime paths of every function in file, keyed
+// by the names Funcs gives them.
+func FilePrimePaths(file *ast.File) (map[string][][]int, error) {
+	paths := make(map[string][][]int)
+	for _, fn := range Funcs(file) {
+		p, err := bodyPrimePaths(fn.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", fn.Name, err)
+		}
+		paths[fn.Name] = p
+	}
+	return paths, nil
+}
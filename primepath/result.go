@@ -0,0 +1,204 @@
+package primepath
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// Options controls how paths are computed from the graph of a function.
+// The zero Options computes every prime path of the graph as built.
+type Options struct {
+	Limits Limits
+	// Collapse merges straight-line chains of nodes, as described by
+	// Collapse.
+	Collapse bool
+	// Reverse computes paths over the transpose of the graph.
+	Reverse bool
+	// EntryOnly keeps only the paths starting at the entry, as described
+	// by FindEntryPaths, in place of the prime paths.
+	EntryOnly bool
+	// SimplePaths also computes every simple path.
+	SimplePaths bool
+	// Cycles also computes every elementary cycle.
+	Cycles bool
+	// GraphOnly builds the graph without computing any paths; SimplePaths
+	// and Cycles are ignored.
+	GraphOnly bool
+	// MergeEmptyCases merges empty switch cases, as described by
+	// MergeEmptyCases.
+	MergeEmptyCases bool
+}
+
+// Result holds everything computed for one function.
+type Result struct {
+	File    string
+	Package string
+	// Func is the name Funcs gives the function.
+	Func string
+	// Pos is where the function is declared.
+	Pos  token.Position
+	Fset *token.FileSet
+	CFG  *cfg.CFG
+	// Graph is built from CFG by BuildGraph, then collapsed and reversed
+	// as Options ask.
+	Graph Graph
+	// Blocks maps the nodes of the graph BuildGraph returned to their
+	// blocks.
+	Blocks []*cfg.Block
+	// Chains maps each node of Graph to the nodes of that graph it merges,
+	// when collapsed; otherwise it is nil and Graph is that graph, or its
+	// transpose.
+	Chains   [][]int
+	Reversed bool
+	// Dead lists the unreachable blocks of CFG that hold statements.
+	Dead []*cfg.Block
+	// MergedCases is the number of case expressions MergeEmptyCases
+	// removed from CFG.
+	MergedCases int
+	// Branches resolves the condition behind each edge of CFG.
+	Branches *Branches
+	// Candidates are the paths PrimePaths were filtered from.
+	Candidates [][]int
+	// SimplePaths are the distinct simple paths, when requested.
+	SimplePaths [][]int
+	// Cycles are the elementary cycles, when requested.
+	Cycles [][]int
+	// PrimePaths are the longest paths from the entry instead when
+	// EntryOnly is set.
+	PrimePaths [][]int
+	EntryOnly  bool
+	// GraphOnly reports that no paths were computed.
+	GraphOnly bool
+	// Truncated reports that path enumeration hit Limits, so Candidates,
+	// SimplePaths and PrimePaths are incomplete.
+	Truncated bool
+}
+
+// Analyze parses filename and analyzes each of its functions with the zero
+// Config and Options.
+func Analyze(filename string) ([]Result, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, fn := range Funcs(file) {
+		res, err := (&Config{}).AnalyzeFunc(fset, filename, file, fn, Options{})
+		if err != nil {
+			return nil, fmt.Errorf("%s:%s: %w", filename, fn.Name, err)
+		}
+		results = append(results, *res)
+	}
+	return results, nil
+}
+
+// AnalyzeFunc analyzes fn, a function of file, which was parsed from
+// filename. When path enumeration hits opts.Limits it still returns the
+// result, with Truncated set.
+func (c *Config) AnalyzeFunc(fset *token.FileSet, filename string, file *ast.File, fn Func, opts Options) (*Result, error) {
+	g, err := c.BodyCFG(fn.Body)
+	if err != nil {
+		return nil, err
+	}
+	merged := 0
+	if opts.MergeEmptyCases {
+		merged = MergeEmptyCases(g)
+	}
+	graph, blocks, err := BuildGraph(g)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{
+		File:        filename,
+		Package:     file.Name.Name,
+		Func:        fn.Name,
+		Pos:         fset.Position(fn.Node.Pos()),
+		Fset:        fset,
+		CFG:         g,
+		Blocks:      blocks,
+		Dead:        DeadBlocks(g),
+		MergedCases: merged,
+		Branches:    NewBranches(g, fn.Body),
+		Reversed:    opts.Reverse,
+		EntryOnly:   opts.EntryOnly,
+		GraphOnly:   opts.GraphOnly,
+	}
+	res.Branches.Info = c.Info
+	if opts.Collapse {
+		graph, res.Chains = Collapse(graph)
+	}
+	if opts.Reverse {
+		graph = Reverse(graph)
+	}
+	res.Graph = graph
+	if opts.GraphOnly {
+		return res, nil
+	}
+
+	find := FindCandidatePaths
+	if opts.EntryOnly {
+		find = FindEntryPaths
+	}
+	res.Candidates, err = find(graph, opts.Limits)
+	res.Truncated = errors.Is(err, ErrTruncated)
+	res.PrimePaths = FilterPrimePaths(res.Candidates)
+	if opts.SimplePaths {
+		res.SimplePaths, err = SimplePaths(graph, opts.Limits)
+		res.Truncated = res.Truncated || errors.Is(err, ErrTruncated)
+	}
+	if opts.Cycles {
+		res.Cycles = Cycles(graph)
+	}
+	return res, nil
+}
+
+// Entries returns the initial nodes of the graph as built, before any
+// reversal, which a well-formed function has exactly one of.
+func (r *Result) Entries() []int {
+	if r.Reversed {
+		return FinalNodes(r.Graph)
+	}
+	return InitialNodes(r.Graph)
+}
+
+// Complexity returns the cyclomatic complexity of the graph as built,
+// before any reversal.
+func (r *Result) Complexity() int {
+	if r.Reversed {
+		return Complexity(Reverse(r.Graph))
+	}
+	return Complexity(r.Graph)
+}
+
+// NodeBlocks returns the blocks node n of Graph stands for.
+func (r *Result) NodeBlocks(n int) []*cfg.Block {
+	if r.Chains == nil {
+		return r.Blocks[n : n+1]
+	}
+	blocks := make([]*cfg.Block, len(r.Chains[n]))
+	for i, m := range r.Chains[n] {
+		blocks[i] = r.Blocks[m]
+	}
+	return blocks
+}
+
+// ExpandPath maps path, a path over Graph, to the nodes Blocks is indexed
+// by, in the order control flows through them.
+func (r *Result) ExpandPath(path []int) []int {
+	if r.Reversed {
+		path = slices.Clone(path)
+		slices.Reverse(path)
+	}
+	if r.Chains == nil {
+		return path
+	}
+	return Expand(path, r.Chains)
+}
@@ -0,0 +1,75 @@
+package primepath
+
+import (
+	"go/ast"
+	"go/token"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// SplitConditions rewrites g, the CFG of body, so that each operand of a
+// short-circuit && or || in a branch condition is tested in its own block.
+//
+// A block whose condition is a && b keeps a as its condition; when a is
+// true control moves to a new block testing b, which has the original
+// successors, and when a is false it goes straight to the false successor.
+// a || b is split the same way with the roles of true and false swapped.
+// Operands are split recursively and parentheses are ignored, so every
+// resulting condition is an operand that is not itself a && or ||.
+//
+// New blocks take the Kind and Stmt of the block they were split from and
+// are appended to g.Blocks. Case expressions of a switch with a tag are
+// compared with the tag rather than tested, so they are left alone.
+func SplitConditions(g *cfg.CFG, body *ast.BlockStmt) {
+	tagged := make(map[ast.Node]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		if sw, ok := n.(*ast.SwitchStmt); ok && sw.Tag != nil {
+			for _, cc := range sw.Body.List {
+				for _, e := range cc.(*ast.CaseClause).List {
+					tagged[e] = true
+				}
+			}
+		}
+		return true
+	})
+
+	// Blocks appended while splitting are split as they are created.
+	for _, block := range g.Blocks {
+		if len(block.Succs) == 2 && len(block.Nodes) > 0 && !tagged[block.Nodes[len(block.Nodes)-1]] {
+			splitBlock(g, block)
+		}
+	}
+}
+
+func splitBlock(g *cfg.CFG, block *cfg.Block) {
+	last := len(block.Nodes) - 1
+	cond, ok := block.Nodes[last].(ast.Expr)
+	if !ok {
+		return
+	}
+	bin, ok := ast.Unparen(cond).(*ast.BinaryExpr)
+	if !ok || (bin.Op != token.LAND && bin.Op != token.LOR) {
+		return
+	}
+
+	rhs := &cfg.Block{
+		Nodes: []ast.Node{bin.Y},
+		Succs: []*cfg.Block{block.Succs[0], block.Succs[1]},
+		Index: nextIndex(g),
+		Live:  block.Live,
+		Kind:  block.Kind,
+		Stmt:  block.Stmt,
+	}
+	g.Blocks = append(g.Blocks, rhs)
+
+	nodes := append([]ast.Node(nil), block.Nodes[:last]...)
+	block.Nodes = append(nodes, bin.X)
+	if bin.Op == token.LAND {
+		block.Succs = []*cfg.Block{rhs, block.Succs[1]}
+	} else {
+		block.Succs = []*cfg.Block{block.Succs[0], rhs}
+	}
+
+	splitBlock(g, block)
+	splitBlock(g, rhs)
+}
@@ -0,0 +1,118 @@
+package primepath
+
+import (
+	"go/ast"
+	"slices"
+
+	"golang.org/x/tools/go/cfg"
+)
+
+// MergeEmptyCases merges runs of adjacent cases with empty bodies in each
+// switch of g into the first of the run, as if their expressions were
+// listed in a single case. The tests of the others are bypassed and
+// removed from g, along with their bodies. Only switches with a tag whose
+// case expressions are all literal constants are merged: at most one of
+// their cases can match, so the order of the tests makes no difference,
+// while the tests of a switch without a tag may overlap. It returns the
+// number of case expressions merged away.
+func MergeEmptyCases(g *cfg.CFG) int {
+	var body *ast.BlockStmt
+	bodies := make(map[*ast.CaseClause]*cfg.Block)
+	preds := make(map[*cfg.Block][]*cfg.Block)
+	for _, block := range g.Blocks {
+		switch block.Kind {
+		case cfg.KindBody:
+			body, _ = block.Stmt.(*ast.BlockStmt)
+		case cfg.KindSwitchCaseBody:
+			if cc, ok := block.Stmt.(*ast.CaseClause); ok {
+				bodies[cc] = block
+			}
+		}
+		for _, succ := range block.Succs {
+			preds[succ] = append(preds[succ], block)
+		}
+	}
+	if body == nil {
+		return 0
+	}
+
+	// bypass removes the case whose body is caseBody, reporting false if
+	// none of its tests is live.
+	removed := make(map[*cfg.Block]bool)
+	bypass := func(caseBody *cfg.Block) bool {
+		var tests []*cfg.Block
+		for _, block := range SortedBlocks(g) {
+			if block.Live && len(block.Succs) == 2 && block.Succs[0] == caseBody {
+				tests = append(tests, block)
+			}
+		}
+		if len(tests) == 0 {
+			return false
+		}
+		// A case with several expressions tests them in turn, each
+		// test's false successor being the next.
+		for _, test := range tests {
+			next := test.Succs[1]
+			for _, pred := range preds[test] {
+				for i, succ := range pred.Succs {
+					if succ == test {
+						pred.Succs[i] = next
+					}
+				}
+				preds[next] = append(preds[next], pred)
+			}
+			preds[next] = slices.DeleteFunc(preds[next], func(b *cfg.Block) bool { return b == test })
+			removed[test] = true
+		}
+		removed[caseBody] = true
+		return true
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.SwitchStmt:
+			if n.Tag == nil {
+				return true
+			}
+			// run reports that the previous case is empty and kept.
+			run := false
+			for _, stmt := range n.Body.List {
+				cc := stmt.(*ast.CaseClause)
+				switch {
+				case !emptyConstCase(cc) || bodies[cc] == nil:
+					run = false
+				case !run:
+					run = true
+				default:
+					run = bypass(bodies[cc])
+				}
+			}
+		}
+		return true
+	})
+
+	g.Blocks = slices.DeleteFunc(g.Blocks, func(b *cfg.Block) bool { return removed[b] })
+	merged := 0
+	for block := range removed {
+		if block.Kind != cfg.KindSwitchCaseBody {
+			merged++
+		}
+	}
+	return merged
+}
+
+// emptyConstCase reports whether cc is a case other than the default with
+// an empty body, matching only literal constants.
+func emptyConstCase(cc *ast.CaseClause) bool {
+	if cc.List == nil || len(cc.Body) > 0 {
+		return false
+	}
+	for _, x := range cc.List {
+		if literalValue(x) == nil {
+			return false
+		}
+	}
+	return true
+}
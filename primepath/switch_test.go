@@ -0,0 +1,90 @@
+package primepath
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMergeEmptyCases(t *testing.T) {
+	tests := []struct {
+		name, src string
+		merged    int
+		graph     Graph
+	}{
+		{
+			// 1, 2 and 3 merge into 0, the first of the run, leaving its
+			// test at node 0 and those of 4 and 5 at nodes 4 and 6; 5 is
+			// not adjacent to the run.
+			name: "tagged",
+			src: `package p
+
+func f(d int) (n int) {
+	switch d {
+	case 0:
+	case 1, 2:
+	case 3:
+	case 4:
+		n++
+	case 5:
+	}
+	return n
+}
+`,
+			merged: 3,
+			graph:  Graph{{2, 4}, {}, {1}, {1}, {3, 6}, {1}, {5, 7}, {1}},
+		},
+		{
+			// Moving x > 1 ahead of x > 3 would send 4 to the wrong case.
+			name: "tagless",
+			src: `package p
+
+func f(x int) (y int) {
+	switch {
+	case x > 5:
+	case x > 3:
+		y++
+	case x > 1:
+	default:
+		y--
+	}
+	return y
+}
+`,
+			graph: Graph{{2, 4}, {}, {1}, {1}, {3, 6}, {1}, {5, 8}, {1}, {7}},
+		},
+		{
+			name: "not constant",
+			src: `package p
+
+func f(d, e int) (n int) {
+	switch d {
+	case 0:
+	case e:
+	default:
+		n++
+	}
+	return n
+}
+`,
+			graph: Graph{{2, 4}, {}, {1}, {1}, {3, 6}, {1}, {5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewCFG(parseFunc(t, tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if merged := MergeEmptyCases(g); merged != tt.merged {
+				t.Errorf("merged = %d, want %d", merged, tt.merged)
+			}
+			graph, _, err := BuildGraph(g)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.EqualFunc(graph, tt.graph, slices.Equal) {
+				t.Errorf("graph = %v, want %v", graph, tt.graph)
+			}
+		})
+	}
+}
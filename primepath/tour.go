@@ -0,0 +1,327 @@
+package primepath
+
+import "slices"
+
+// TourKind classifies how a test path tours a requirement.
+type TourKind int
+
+const (
+	// Infeasible means no complete test path tours the requirement.
+	Infeasible TourKind = iota
+	// Direct means the test path contains the requirement as a subpath.
+	Direct
+	// Sidetrip means the test path follows the requirement but leaves it
+	// at one of its nodes along a cycle, returning to the same node
+	// before going on, because every direct tour was rejected as
+	// infeasible.
+	Sidetrip
+)
+
+func (k TourKind) String() string {
+	switch k {
+	case Direct:
+		return "direct"
+	case Sidetrip:
+		return "sidetrip"
+	}
+	return "infeasible"
+}
+
+// Tour is a complete test path, from an initial node to a final node,
+// touring a requirement. For functions without a final node, tours end at a
+// loop head instead.
+type Tour struct {
+	Path []int
+	Kind TourKind
+}
+
+// Tours returns a direct tour of each of primePaths over graph.
+// Requirements that cannot be toured at all have Kind Infeasible and a nil
+// Path.
+func Tours(graph [][]int, primePaths [][]int) []Tour {
+	return ToursEndingAt(graph, primePaths, ExitNodes(graph))
+}
+
+// ToursEndingAt is Tours with complete test paths ending at one of ends
+// rather than at any final node, such as only the nodes that return
+// normally.
+func ToursEndingAt(graph [][]int, primePaths [][]int, ends []int) []Tour {
+	return FeasibleTours(graph, primePaths, ends, nil)
+}
+
+// FeasibleTours is ToursEndingAt keeping only the test paths feasible
+// accepts, such as those Branches.Contradiction finds no conflict on. The
+// shortest few simple paths to and from each requirement are tried in
+// turn. When feasible rejects every direct tour they make, the
+// requirement is toured with a sidetrip: an elementary cycle through one
+// of its nodes, shortest first, is inserted there. A nil feasible accepts
+// every path, so tours are always direct.
+func FeasibleTours(graph [][]int, primePaths [][]int, ends []int, feasible func([]int) bool) []Tour {
+	starts := entryNodes(graph)
+	accept := func(path []int) bool {
+		return path != nil && (feasible == nil || feasible(path))
+	}
+
+	var cycles [][]int
+	tours := make([]Tour, len(primePaths))
+	for i, p := range primePaths {
+		// Tours meeting p's nodes only along p read best, so they are
+		// tried first.
+		if path := tourPath(graph, starts, ends, p, true); accept(path) {
+			tours[i] = Tour{Path: path, Kind: Direct}
+			continue
+		}
+		path := tourPath(graph, starts, ends, p, false)
+		if accept(path) {
+			tours[i] = Tour{Path: path, Kind: Direct}
+			continue
+		}
+		if path == nil || feasible == nil {
+			continue
+		}
+
+		prefixes := pathsBetween(graph, starts, p[:1], maxTourEnds)
+		suffixes := pathsBetween(graph, p[len(p)-1:], ends, maxTourEnds)
+		if path := findTour(prefixes, [][]int{p}, suffixes, feasible); path != nil {
+			tours[i] = Tour{Path: path, Kind: Direct}
+			continue
+		}
+
+		if cycles == nil {
+			cycles = Cycles(graph)
+			slices.SortStableFunc(cycles, func(a, b []int) int {
+				return len(a) - len(b)
+			})
+		}
+		if path := findTour(prefixes, sidetrips(p, cycles), suffixes, feasible); path != nil {
+			tours[i] = Tour{Path: path, Kind: Sidetrip}
+		}
+	}
+	return tours
+}
+
+// maxTourEnds bounds the paths to and from a requirement FeasibleTours
+// tries.
+const maxTourEnds = 8
+
+// TourPaths returns, for each index into primePaths, a complete test path
+// from an initial node to a final node of graph that tours the prime path.
+// Prime paths that cannot be toured have no entry in the result.
+func TourPaths(graph [][]int, primePaths [][]int) map[int][]int {
+	paths := make(map[int][]int)
+	for i, tour := range Tours(graph, primePaths) {
+		if tour.Kind != Infeasible {
+			paths[i] = tour.Path
+		}
+	}
+	return paths
+}
+
+// tourPath joins a shortest path from starts to p with a shortest path from
+// p to ends. When direct is set, neither may pass through p's other nodes.
+func tourPath(graph [][]int, starts, ends []int, p []int, direct bool) []int {
+	if len(p) == 0 {
+		return nil
+	}
+
+	var avoid []bool
+	if direct {
+		avoid = make([]bool, len(graph))
+		for _, n := range p {
+			avoid[n] = true
+		}
+	}
+
+	first, last := p[0], p[len(p)-1]
+	prefix := shortestPath(graph, starts, []int{first}, avoid)
+	suffix := shortestPath(graph, []int{last}, ends, avoid)
+	if prefix == nil || suffix == nil {
+		return nil
+	}
+	return joinTour(prefix, p, suffix)
+}
+
+// sidetrips returns the walks that follow p but, after one of its nodes
+// other than the last, go around one of cycles back to that node, in the
+// order of cycles.
+func sidetrips(p []int, cycles [][]int) [][]int {
+	var walks [][]int
+	for _, cycle := range cycles {
+		body := cycle[:len(cycle)-1]
+		for i, n := range p[:len(p)-1] {
+			if at := slices.Index(body, n); at >= 0 {
+				// The cycle rotated to start at n, less n itself.
+				trip := slices.Concat(body[at+1:], body[:at+1])
+				walks = append(walks, slices.Concat(p[:i+1], trip, p[i+1:]))
+			}
+		}
+	}
+	return walks
+}
+
+// findTour returns the first test path feasible accepts made of one of
+// prefixes, one of walks and one of suffixes, trying walks in order, or nil
+// if there is none.
+func findTour(prefixes, walks, suffixes [][]int, feasible func([]int) bool) []int {
+	for _, walk := range walks {
+		for _, prefix := range prefixes {
+			for _, suffix := range suffixes {
+				if path := joinTour(prefix, walk, suffix); feasible(path) {
+					return path
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// pathsBetween returns up to limit of the shortest simple paths from a node
+// of from to a node of to, shortest first, that meet to only at their end.
+func pathsBetween(graph [][]int, from, to []int, limit int) [][]int {
+	target := make([]bool, len(graph))
+	for _, n := range to {
+		target[n] = true
+	}
+
+	var paths [][]int
+	queue := make([][]int, 0, len(from))
+	for _, n := range from {
+		queue = append(queue, []int{n})
+	}
+	// Breadth-first search over paths finds them in order of length, but
+	// the queue can grow exponentially, so it gives up eventually.
+	for steps := 0; len(queue) > 0 && len(paths) < limit && steps < limit*len(graph)*len(graph); steps++ {
+		path := queue[0]
+		queue = queue[1:]
+		last := path[len(path)-1]
+		if target[last] {
+			paths = append(paths, path)
+			continue
+		}
+		for _, next := range graph[last] {
+			if !slices.Contains(path, next) {
+				queue = append(queue, append(slices.Clone(path), next))
+			}
+		}
+	}
+	return paths
+}
+
+// joinTour joins prefix, ending at the first node of walk, walk and suffix,
+// starting at its last node.
+func joinTour(prefix, walk, suffix []int) []int {
+	tour := make([]int, 0, len(prefix)+len(walk)+len(suffix)-2)
+	tour = append(tour, prefix[:len(prefix)-1]...)
+	tour = append(tour, walk...)
+	return append(tour, suffix[1:]...)
+}
+
+// shortestPath returns a shortest path from any node in from to any node in
+// to, or nil if there is none. Nodes marked in avoid are never passed
+// through, though they may still start or end the path.
+func shortestPath(graph [][]int, from, to []int, avoid []bool) []int {
+	target := make([]bool, len(graph))
+	for _, n := range to {
+		target[n] = true
+	}
+	blocked := func(n int) bool {
+		return avoid != nil && avoid[n] && !target[n]
+	}
+
+	parent := make([]int, len(graph))
+	for i := range parent {
+		parent[i] = -2
+	}
+	queue := make([]int, 0, len(graph))
+	for _, n := range from {
+		if parent[n] == -2 {
+			parent[n] = -1
+			queue = append(queue, n)
+		}
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		if target[node] {
+			var path []int
+			for n := node; n != -1; n = parent[n] {
+				path = append(path, n)
+			}
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, next := range graph[node] {
+			if parent[next] == -2 && !blocked(next) {
+				parent[next] = node
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// MinimalTestPaths returns a small set of complete test paths that together
+// tour every tourable prime path. Candidates are the tours of each prime
+// path, chosen greedily by how many still uncovered prime paths they
+// contain.
+func MinimalTestPaths(graph [][]int, primePaths [][]int) [][]int {
+	return MinimalTestPathsEndingAt(graph, primePaths, ExitNodes(graph))
+}
+
+// MinimalTestPathsEndingAt is MinimalTestPaths with the test paths ending
+// at one of ends, as for ToursEndingAt.
+func MinimalTestPathsEndingAt(graph [][]int, primePaths [][]int, ends []int) [][]int {
+	var candidates [][]int
+	uncovered := make(map[int]bool)
+	for i, tour := range ToursEndingAt(graph, primePaths, ends) {
+		if tour.Kind != Infeasible {
+			candidates = append(candidates, tour.Path)
+			uncovered[i] = true
+		}
+	}
+
+	covers := make([][]int, len(candidates))
+	for i, c := range candidates {
+		covers[i] = CoveredBy(c, primePaths)
+	}
+
+	var chosen [][]int
+	for len(uncovered) > 0 {
+		best, bestCount := -1, 0
+		for i := range candidates {
+			count := 0
+			for _, req := range covers[i] {
+				if uncovered[req] {
+					count++
+				}
+			}
+			if count > bestCount {
+				best, bestCount = i, count
+			}
+		}
+		if best < 0 {
+			break
+		}
+
+		chosen = append(chosen, candidates[best])
+		for _, req := range covers[best] {
+			delete(uncovered, req)
+		}
+	}
+	return chosen
+}
+
+// CoveredBy returns the indices of the requirements in reqs that testPath
+// contains as a subpath.
+func CoveredBy(testPath []int, reqs [][]int) []int {
+	var covered []int
+	for i, req := range reqs {
+		if containsSubpath(testPath, req) {
+			covered = append(covered, i)
+		}
+	}
+	return covered
+}
@@ -0,0 +1,64 @@
+package primepath
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestToursDirect(t *testing.T) {
+	// retry in testdata/goto.go: a loop 1 -> 2 -> 1 made with goto.
+	graph := [][]int{{1}, {2, 3}, {1}, {}}
+	primePaths := [][]int{{0, 1, 2}, {0, 1, 3}, {1, 2, 1}, {2, 1, 3}}
+	want := []Tour{
+		{Path: []int{0, 1, 2, 1, 3}, Kind: Direct},
+		{Path: []int{0, 1, 3}, Kind: Direct},
+		{Path: []int{0, 1, 2, 1, 3}, Kind: Direct},
+		{Path: []int{0, 1, 2, 1, 3}, Kind: Direct},
+	}
+	if got := Tours(graph, primePaths); !slices.EqualFunc(got, want, equalTour) {
+		t.Errorf("Tours = %v, want %v", got, want)
+	}
+}
+
+func TestFeasibleTours(t *testing.T) {
+	results, err := Analyze(filepath.Join("..", "testdata", "sidetrip.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := results[0]
+	feasible := func(path []int) bool {
+		_, ok := res.Branches.Contradiction(res.Fset, res.Blocks, path)
+		return !ok
+	}
+
+	want := []Tour{
+		{Path: []int{0, 1, 2, 5, 3, 5, 4}, Kind: Direct},
+		// x > 0 holds at the if, so the loop runs before it is left.
+		{Path: []int{0, 1, 2, 5, 3, 5, 4}, Kind: Sidetrip},
+		// x > 0 fails at the if, so the loop never runs.
+		{Kind: Infeasible},
+		{Path: []int{0, 2, 5, 4}, Kind: Direct},
+		{Path: []int{0, 1, 2, 5, 3, 5, 3, 5, 4}, Kind: Direct},
+		{Path: []int{0, 1, 2, 5, 3, 5, 4}, Kind: Direct},
+	}
+	got := FeasibleTours(res.Graph, res.PrimePaths, ExitNodes(res.Graph), feasible)
+	if !slices.EqualFunc(got, want, equalTour) {
+		t.Errorf("FeasibleTours(%v) = %v, want %v", res.PrimePaths, got, want)
+	}
+}
+
+func equalTour(a, b Tour) bool {
+	return a.Kind == b.Kind && slices.Equal(a.Path, b.Path)
+}
+
+func TestToursWithoutExit(t *testing.T) {
+	// serve in testdata/infinite.go.
+	graph := [][]int{{1}, {2, 3}, {1}, {1}}
+	primePaths := [][]int{{0, 1, 2}, {1, 2, 1}, {2, 1, 3}}
+	for i, tour := range Tours(graph, primePaths) {
+		if tour.Kind == Infeasible || tour.Path[len(tour.Path)-1] != 1 {
+			t.Errorf("tour of %v = %v, want one ending at loop head 1", primePaths[i], tour)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+)
+
+// snippetFunc names the function statements given to -e are wrapped in.
+const snippetFunc = "snippet"
+
+// wrapSnippet turns the source given to -e into a file: as it is if it has
+// a package clause, otherwise as declarations of a package p or, failing
+// that, as the body of a function named snippet. The wrapping is added on
+// the first line, so line numbers stay those of the snippet.
+func wrapSnippet(src string) (string, error) {
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "snippet", src, parser.PackageClauseOnly); err == nil {
+		return src, nil
+	}
+	const prefix = "package p; "
+	decls := prefix + src
+	_, err := parser.ParseFile(fset, "snippet", decls, 0)
+	if err == nil {
+		return decls, nil
+	}
+	open := prefix + "func " + snippetFunc + "() { "
+	body := open + src + "\n}"
+	_, berr := parser.ParseFile(fset, "snippet", body, 0)
+	if berr == nil {
+		return body, nil
+	}
+
+	// A snippet that does not start with a declaration is taken for
+	// statements, whose error is the telling one.
+	err = unwrapPositions(err, len(prefix))
+	if list, ok := err.(scanner.ErrorList); ok && list[0].Pos.Line == 1 && list[0].Pos.Column == 1 {
+		err = unwrapPositions(berr, len(open))
+	}
+	return "", fmt.Errorf("-e: not a Go file, declarations or function body: %w", err)
+}
+
+// unwrapPositions moves the positions of err on the first line back by the
+// n bytes wrapSnippet added there.
+func unwrapPositions(err error, n int) error {
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, e := range list {
+			if e.Pos.Line == 1 {
+				e.Pos.Column -= n
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+// lengthHistogram counts prime paths by length, in edges.
+type lengthHistogram map[int]int
+
+func (h lengthHistogram) add(paths [][]int) {
+	for _, path := range paths {
+		h[len(path)-1]++
+	}
+}
+
+// write prints a line per length and the minimum, maximum and mean
+// length.
+func (h lengthHistogram) write(w io.Writer) {
+	var lengths []int
+	paths, total := 0, 0
+	for length, n := range h {
+		lengths = append(lengths, length)
+		paths += n
+		total += length * n
+	}
+	if paths == 0 {
+		fmt.Fprintln(w, "  no prime paths")
+		return
+	}
+	slices.Sort(lengths)
+	for _, length := range lengths {
+		fmt.Fprintf(w, "  length %d: %d\n", length, h[length])
+	}
+	fmt.Fprintf(w, "  min=%d max=%d mean=%.2f\n",
+		lengths[0], lengths[len(lengths)-1], float64(total)/float64(paths))
+}
+
+// statsFormatter prints the distribution of prime path lengths of each
+// function and of all of them together.
+type statsFormatter struct {
+	w         io.Writer
+	functions int
+	total     lengthHistogram
+}
+
+func (f *statsFormatter) Function(r *funcResult) error {
+	f.functions++
+	h := make(lengthHistogram)
+	h.add(r.PrimePaths)
+	f.total.add(r.PrimePaths)
+
+	fmt.Fprintf(f.w, "=== Function: %s:%s (%s) ===\n", r.File, r.label(), r.Pos)
+	if r.Truncated {
+		fmt.Fprintln(f.w, "  (incomplete: path enumeration was truncated)")
+	}
+	h.write(f.w)
+	_, err := fmt.Fprintln(f.w)
+	return err
+}
+
+func (f *statsFormatter) Close() error {
+	fmt.Fprintf(f.w, "=== Total: %d functions ===\n", f.functions)
+	f.total.write(f.w)
+	return nil
+}
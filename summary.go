@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+)
+
+type metrics struct {
+	blocks     int
+	edges      int
+	candidates int
+	primePaths int
+	complexity int
+	// entries is the number of initial nodes, which should be one.
+	entries int
+	// depth is the loop nesting depth; the total holds the largest.
+	depth int
+	// testPaths is the number of minimal test paths touring the prime
+	// paths.
+	testPaths int
+}
+
+func (m *metrics) add(o metrics) {
+	m.blocks += o.blocks
+	m.edges += o.edges
+	m.candidates += o.candidates
+	m.primePaths += o.primePaths
+	m.complexity += o.complexity
+	m.entries += o.entries
+	m.depth = max(m.depth, o.depth)
+	m.testPaths += o.testPaths
+}
+
+func (m metrics) String() string {
+	return fmt.Sprintf("blocks=%d edges=%d candidates=%d prime=%d complexity=%d depth=%d entries=%d tests=%d",
+		m.blocks, m.edges, m.candidates, m.primePaths, m.complexity, m.depth, m.entries, m.testPaths)
+}
+
+func resultMetrics(r *funcResult) metrics {
+	m := metrics{
+		blocks:     len(r.Graph),
+		candidates: len(r.Candidates),
+		primePaths: len(r.PrimePaths),
+		complexity: r.Complexity(),
+		depth:      primepath.NestingDepth(r.Graph),
+		entries:    len(r.Entries()),
+		testPaths:  len(primepath.MinimalTestPathsEndingAt(r.Graph, r.PrimePaths, r.ends())),
+	}
+	for _, succs := range r.Graph {
+		m.edges += len(succs)
+	}
+	return m
+}
+
+func (m metrics) json() jsonMetrics {
+	return jsonMetrics{
+		Blocks:     m.blocks,
+		Edges:      m.edges,
+		Candidates: m.candidates,
+		PrimePaths: m.primePaths,
+		Complexity: m.complexity,
+		Depth:      m.depth,
+		Entries:    m.entries,
+		TestPaths:  m.testPaths,
+	}
+}
+
+type jsonMetrics struct {
+	Blocks     int `json:"blocks"`
+	Edges      int `json:"edges"`
+	Candidates int `json:"candidate_paths"`
+	PrimePaths int `json:"prime_paths"`
+	Complexity int `json:"complexity"`
+	Depth      int `json:"nesting_depth"`
+	Entries    int `json:"entries"`
+	TestPaths  int `json:"test_paths"`
+}
+
+type jsonSummaryFunction struct {
+	File       string `json:"file"`
+	Function   string `json:"function"`
+	TypeParams string `json:"type_params,omitempty"`
+	Line       int    `json:"line"`
+	Column     int    `json:"column"`
+	Truncated  bool   `json:"truncated,omitempty"`
+	jsonMetrics
+}
+
+type jsonSummary struct {
+	Functions []jsonSummaryFunction `json:"functions"`
+	Total     jsonMetrics           `json:"total"`
+}
+
+// summaryFormatter prints a line of metrics per function and their total,
+// or with json a single object holding both.
+type summaryFormatter struct {
+	w         io.Writer
+	json      bool
+	functions []jsonSummaryFunction
+	total     metrics
+}
+
+func (f *summaryFormatter) Function(r *funcResult) error {
+	m := resultMetrics(r)
+	f.functions = append(f.functions, jsonSummaryFunction{
+		File:        r.File,
+		Function:    r.Func,
+		TypeParams:  r.TypeParams,
+		Line:        r.Pos.Line,
+		Column:      r.Pos.Column,
+		Truncated:   r.Truncated,
+		jsonMetrics: m.json(),
+	})
+	f.total.add(m)
+	if f.json {
+		return nil
+	}
+	var notes string
+	if r.Truncated {
+		notes += " (truncated)"
+	}
+	if r.GraphOnly {
+		notes += " (graph only)"
+	}
+	if m.entries > 1 {
+		notes += " (multiple entries)"
+	}
+	if trivial(&r.Result) {
+		notes += " (0 branches)"
+	}
+	_, err := fmt.Fprintf(f.w, "%s: %s: %s%s\n", r.Pos, r.label(), m, notes)
+	return err
+}
+
+func (f *summaryFormatter) Close() error {
+	if f.json {
+		functions := f.functions
+		if functions == nil {
+			functions = []jsonSummaryFunction{}
+		}
+		enc := json.NewEncoder(f.w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(jsonSummary{Functions: functions, Total: f.total.json()})
+	}
+	_, err := fmt.Fprintf(f.w, "total: functions=%d %s\n", len(f.functions), f.total)
+	return err
+}
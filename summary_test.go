@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// analyze runs the analysis of the testdata file name through out with
+// the default options.
+func analyze(t *testing.T, name string, out formatter) {
+	t.Helper()
+	r := &runner{opts: options{criterion: "prime"}, out: out}
+	if err := r.processFile(filepath.Join("testdata", name)); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSummaryJSONDepth(t *testing.T) {
+	var buf bytes.Buffer
+	analyze(t, "nested.go", &summaryFormatter{w: &buf, json: true})
+	var summary jsonSummary
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Functions) != 2 {
+		t.Fatalf("summary has %d functions, want 2", len(summary.Functions))
+	}
+	// Both functions nest one loop in another.
+	for _, fn := range summary.Functions {
+		if fn.Depth != 2 {
+			t.Errorf("%s: nesting_depth = %d, want 2", fn.Function, fn.Depth)
+		}
+	}
+	if summary.Total.Depth != 2 {
+		t.Errorf("total nesting_depth = %d, want 2", summary.Total.Depth)
+	}
+}
+
+func TestSummaryPositions(t *testing.T) {
+	var text, js bytes.Buffer
+	analyze(t, "nested.go", &summaryFormatter{w: &text})
+	analyze(t, "nested.go", &summaryFormatter{w: &js, json: true})
+
+	want := filepath.Join("testdata", "nested.go") + ":13:1: whileNested: "
+	if !strings.Contains(text.String(), "\n"+want) {
+		t.Errorf("summary has no line starting %q:\n%s", want, text.String())
+	}
+	var summary jsonSummary
+	if err := json.Unmarshal(js.Bytes(), &summary); err != nil {
+		t.Fatal(err)
+	}
+	if len(summary.Functions) != 2 {
+		t.Fatalf("summary has %d functions, want 2", len(summary.Functions))
+	}
+	if fn := summary.Functions[1]; fn.Line != 13 || fn.Column != 1 {
+		t.Errorf("%s is at %d:%d, want 13:1", fn.Function, fn.Line, fn.Column)
+	}
+}
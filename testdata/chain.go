@@ -0,0 +1,23 @@
+package sample
+
+// pump has a labeled statement in its loop body, so the body, the label
+// and the post statement form a chain that -collapse merges into one node.
+func pump(n int) int {
+	total := 0
+	for i := 0; i < n; i++ {
+		total += i
+	next:
+		if total > 100 {
+			break next
+		}
+	}
+	return total
+}
+
+// relay runs its calls as one chain once -splice-defers adds the deferred
+// calls after the return.
+func relay(ch chan int) {
+	defer close(ch)
+	defer println("done")
+	ch <- 1
+}
@@ -0,0 +1,31 @@
+package sample
+
+func apply(xs []int) []int {
+	double := func(x int) int {
+		if x < 0 {
+			return 0
+		}
+		return x * 2
+	}
+
+	out := make([]int, 0, len(xs))
+	for _, x := range xs {
+		out = append(out, double(x))
+	}
+
+	func() {
+		for i := range out {
+			defer func() { out[i]++ }()
+		}
+	}()
+	return out
+}
+
+// pick declares two function literals on one line, numbered by column.
+func pick(neg bool) func(int) int {
+	negate, id := func(x int) int { return -x }, func(x int) int { return x }
+	if neg {
+		return negate
+	}
+	return id
+}
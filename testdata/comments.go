@@ -0,0 +1,16 @@
+package sample
+
+func drainQueue(queue []int) int {
+	handled := 0
+loop:
+	for len(queue) > 0 {
+		// Take from the front so items are handled in order.
+		item := queue[0]
+		queue = queue[1:]
+		if item < 0 {
+			continue loop // negative items are placeholders
+		}
+		handled++
+	}
+	return handled
+}
@@ -0,0 +1,37 @@
+package sample
+
+func inRange(x, lo, hi int) bool {
+	if x >= lo && x <= hi {
+		return true
+	}
+	return false
+}
+
+func either(a, b, c bool) int {
+	if (a && b) || c {
+		return 1
+	}
+	return 0
+}
+
+func scan(xs []int, limit int) int {
+	i := 0
+	for i < len(xs) && xs[i] < limit {
+		i++
+	}
+	return i
+}
+
+func classify(x int, ok bool) string {
+	switch {
+	case x > 0 && ok:
+		return "positive"
+	case x < 0 || !ok:
+		return "negative"
+	}
+	switch x {
+	case 1, 2:
+		return "small"
+	}
+	return "zero"
+}
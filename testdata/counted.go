@@ -0,0 +1,11 @@
+package sample
+
+// count is a plain counted loop: the condition block branches to the body,
+// which runs the post statement and loops back, and to the exit.
+func count(n int) int {
+	sum := 0
+	for i := 0; i < n; i++ {
+		sum += i
+	}
+	return sum
+}
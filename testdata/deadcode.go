@@ -0,0 +1,12 @@
+package sample
+
+func deadAfterReturn(x int) int {
+	if x > 0 {
+		return x
+		x++
+	}
+	for i := 0; i < x; i++ {
+		x--
+	}
+	return x
+}
@@ -0,0 +1,48 @@
+package sample
+
+import "os"
+
+func fallsOff(x int) {
+	if x > 0 {
+		println("positive")
+	}
+	println("done")
+}
+
+func cleanup(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer println("closing", name)
+	if name == "" {
+		return nil
+	}
+	println("read", name)
+	return nil
+}
+
+func closeAll(names []string) {
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+	}
+	println("opened", len(names))
+}
+
+// openAll returns from inside the loop as well as after it, so both
+// returns run the deferred calls of the earlier iterations.
+func openAll(names []string) error {
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+	}
+	return nil
+}
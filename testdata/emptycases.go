@@ -0,0 +1,31 @@
+package sample
+
+// weekday has runs of empty cases, which -merge-empty-cases folds into
+// the first of them.
+func weekday(d int) bool {
+	weekend := false
+	switch d {
+	case 0:
+	case 1, 2:
+	case 3:
+	case 4:
+	case 5:
+		weekend = true
+	case 6:
+		weekend = true
+	}
+	return !weekend
+}
+
+// token's switch has no tag, so its empty cases are left alone: moving
+// a test ahead of another could change which case matches.
+func token(c byte) string {
+	switch {
+	case c == ' ':
+	case c == '\t':
+	case c == '\n':
+	default:
+		return "word"
+	}
+	return "space"
+}
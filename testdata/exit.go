@@ -0,0 +1,25 @@
+package sample
+
+import (
+	"log"
+	"os"
+)
+
+func run(args []string) int {
+	if len(args) == 0 {
+		os.Exit(2)
+	}
+	if args[0] == "" {
+		log.Fatal("empty argument")
+	}
+	return len(args)
+}
+
+func mustOpen(name string) *os.File {
+	f, err := os.Open(name)
+	if err != nil {
+		log.Fatal(err)
+		return nil
+	}
+	return f
+}
@@ -0,0 +1,15 @@
+package sample
+
+func grade(n int) int {
+	score := 0
+	switch {
+	case n > 90:
+		score++
+		fallthrough
+	case n > 50:
+		score++
+	default:
+		score--
+	}
+	return score
+}
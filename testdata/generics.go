@@ -0,0 +1,28 @@
+package sample
+
+// Map and mapInts differ only in their type parameters, so their graphs
+// and prime paths are the same.
+func Map[T, U any](xs []T, f func(T) U) []U {
+	out := make([]U, 0, len(xs))
+	for _, x := range xs {
+		out = append(out, f(x))
+	}
+	return out
+}
+
+func mapInts(xs []int, f func(int) int) []int {
+	out := make([]int, 0, len(xs))
+	for _, x := range xs {
+		out = append(out, f(x))
+	}
+	return out
+}
+
+func Max[T interface{ ~int | ~float64 }](xs ...T) (m T) {
+	for i, x := range xs {
+		if i == 0 || x > m {
+			m = x
+		}
+	}
+	return m
+}
@@ -0,0 +1,27 @@
+package sample
+
+func retry(n int) int {
+	tries := 0
+again:
+	tries++
+	if tries < n {
+		goto again
+	}
+	return tries
+}
+
+func skipAhead(x int) int {
+	if x < 0 {
+		goto done
+	}
+	x *= 2
+done:
+	return x
+}
+
+func jumpOverDead(x int) int {
+	goto end
+	x++
+end:
+	return x
+}
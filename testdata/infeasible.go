@@ -0,0 +1,38 @@
+package sample
+
+func sign(x int) string {
+	s := "zero"
+	if x > 0 {
+		s = "positive"
+	}
+	if x < 0 {
+		s = "negative"
+	}
+	return s
+}
+
+func clamp(x int) int {
+	if x > 10 {
+		x = 10
+	}
+	if x < 0 {
+		x = 0
+	}
+	return x
+}
+
+func flags(ok bool, n int) int {
+	if ok && n == 1 {
+		n++
+	}
+	if !ok {
+		return 0
+	}
+	switch n {
+	case 1:
+		return 1
+	case 2:
+		return 2
+	}
+	return n
+}
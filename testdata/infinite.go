@@ -0,0 +1,16 @@
+package sample
+
+func spin() {
+	for {
+	}
+}
+
+func serve(reqs chan int) {
+	for {
+		r := <-reqs
+		if r < 0 {
+			continue
+		}
+		r++
+	}
+}
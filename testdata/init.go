@@ -0,0 +1,22 @@
+package sample
+
+var ready bool
+
+func init() {
+	ready = true
+}
+
+func init() {
+	if !ready {
+		panic("not ready")
+	}
+}
+
+func _() {
+	for range 3 {
+	}
+}
+
+func _() {
+	go func() {}()
+}
@@ -0,0 +1,13 @@
+package sample
+
+func innerLoop(rows [][]int) int {
+	total := 0
+	for i := 0; i < len(rows); i++ {
+		j := 0
+		for j < len(rows[i]) {
+			total += rows[i][j]
+			j++
+		}
+	}
+	return total
+}
@@ -0,0 +1,18 @@
+package sample
+
+func findPair(grid [][]int, want int) bool {
+	found := false
+outer:
+	for _, row := range grid {
+		for _, v := range row {
+			if v < 0 {
+				continue outer
+			}
+			if v == want {
+				found = true
+				break outer
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,46 @@
+package sample
+
+type Celsius float64
+
+type Point struct{ X, Y int }
+
+func (c Celsius) String() string {
+	if c < 0 {
+		return "freezing"
+	}
+	return "warm"
+}
+
+func (p *Point) String() string {
+	if p == nil {
+		return "<nil>"
+	}
+	return "point"
+}
+
+type Stack[E any] struct{ items []E }
+
+func (s *Stack[E]) Push(e E) {
+	if s.items == nil {
+		s.items = make([]E, 0, 8)
+	}
+	s.items = append(s.items, e)
+}
+
+func (s Stack[E]) Len() int {
+	if s.items == nil {
+		return 0
+	}
+	return len(s.items)
+}
+
+type Pair[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+func (p *Pair[K, V]) Swap(other *Pair[K, V]) {
+	if p != other {
+		*p, *other = *other, *p
+	}
+}
@@ -0,0 +1,20 @@
+package sample
+
+func nested(n, m int) int {
+	sum := 0
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			sum += i * j
+		}
+	}
+	return sum
+}
+
+func whileNested(x int) {
+	for x > 0 {
+		for x%2 == 0 {
+			x /= 2
+		}
+		x--
+	}
+}
@@ -0,0 +1,17 @@
+package sample
+
+func mustPositive(x int) int {
+	if x <= 0 {
+		panic("not positive")
+	}
+	return x
+}
+
+func checked(x int) int {
+	if x < 0 {
+		panic("negative")
+		x = -x
+	}
+	println(x)
+	return x * 2
+}
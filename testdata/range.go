@@ -0,0 +1,29 @@
+package sample
+
+func sumSlice(xs []int) (sum int) {
+	for _, x := range xs {
+		sum += x
+	}
+	return sum
+}
+
+func sumMap(m map[string]int) (sum int) {
+	for _, v := range m {
+		sum += v
+	}
+	return sum
+}
+
+func drain(ch <-chan int) (n int) {
+	for range ch {
+		n++
+	}
+	return n
+}
+
+func count(n int) (sum int) {
+	for i := range n {
+		sum += i
+	}
+	return sum
+}
@@ -0,0 +1,25 @@
+package sample
+
+// classify returns from three branches, each a final node of its own.
+func classify(n int) string {
+	if n < 0 {
+		return "negative"
+	}
+	if n == 0 {
+		return "zero"
+	}
+	return "positive"
+}
+
+// classifyDeferred returns from the same three branches after registering a
+// deferred call, which -splice-defers runs after each of them.
+func classifyDeferred(n int) string {
+	defer println("done")
+	if n < 0 {
+		return "negative"
+	}
+	if n == 0 {
+		return "zero"
+	}
+	return "positive"
+}
@@ -0,0 +1,23 @@
+package sample
+
+func poll(in <-chan int, out chan<- int, quit <-chan struct{}) int {
+	select {
+	case v := <-in:
+		return v
+	case out <- 1:
+		return 1
+	case <-quit:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func wait(in <-chan int, quit <-chan struct{}) int {
+	select {
+	case v := <-in:
+		return v
+	case <-quit:
+	}
+	return -1
+}
@@ -0,0 +1,13 @@
+package sample
+
+// settle skips its loop only when x > 0 is false, so the path that takes
+// the if and then skips the loop needs a sidetrip through the loop body.
+func settle(x int) int {
+	if x > 0 {
+		println("positive")
+	}
+	for x > 0 {
+		x--
+	}
+	return x
+}
@@ -0,0 +1,12 @@
+package sample
+
+func empty() {}
+
+func answer() int {
+	return 42
+}
+
+func declareOnly() {
+	var x int
+	_ = x
+}
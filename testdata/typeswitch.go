@@ -0,0 +1,13 @@
+package sample
+
+func kind(x any) string {
+	switch v := x.(type) {
+	case int, string:
+		_ = v
+		return "scalar"
+	case []int:
+		return "slice"
+	default:
+		return "other"
+	}
+}
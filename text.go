@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/amirkhaki/primepathfinder/primepath"
+	"golang.org/x/tools/go/cfg"
+)
+
+type textFormatter struct {
+	w io.Writer
+	// verbose is 0 for requirements only, 1 to add graph info and 2 to
+	// add the CFG blocks too.
+	verbose int
+	color   bool
+	// source renders each requirement as the statements along it.
+	source bool
+	// cycles lists the elementary cycles, or that there are none.
+	cycles bool
+}
+
+func (f *textFormatter) Function(r *funcResult) error {
+	p := &palette{
+		enabled: f.color,
+		initial: nodeSet(primepath.InitialNodes(r.Graph)),
+		final:   nodeSet(primepath.FinalNodes(r.Graph)),
+	}
+	fmt.Fprintf(f.w, "=== Function: %s:%s (%s) ===\n", r.File, r.label(), r.Pos)
+	if trivial(&r.Result) {
+		fmt.Fprintln(f.w, "(no branches: a single path covers the function)")
+	}
+	if r.MergedCases > 0 {
+		fmt.Fprintf(f.w, "(%d empty switch cases merged)\n", r.MergedCases)
+	}
+
+	if f.verbose >= 2 {
+		fmt.Fprintln(f.w, "\nCFG Blocks:")
+		printCFG(f.w, r.CFG, r.Fset, r.Comments, r.Signature, p)
+	}
+	if f.verbose >= 1 || r.GraphOnly {
+		printGraphInfo(f.w, r.Graph, p)
+		if r.Sinks != nil {
+			sinks := joinInts(r.Sinks)
+			if sinks == "" {
+				sinks = "none"
+			}
+			fmt.Fprintf(f.w, "Sinks: %s\n", p.paint(colorFinal, sinks))
+		}
+		if len(r.Dead) > 0 {
+			fmt.Fprintln(f.w, "\nDead Code:")
+			for _, block := range r.Dead {
+				fmt.Fprintf(f.w, "  %s %s\n", p.paint(colorBlock, "block "+strconv.Itoa(int(block.Index))),
+					blockRange(r.Fset, block))
+			}
+		}
+	}
+
+	if r.SimplePaths != nil {
+		fmt.Fprintln(f.w, "\nSimple Paths:")
+		for i, path := range r.SimplePaths {
+			fmt.Fprintf(f.w, "  %s %v\n", p.paint(colorNumber, strconv.Itoa(i+1)+":"), path)
+		}
+	}
+
+	if f.cycles {
+		fmt.Fprintln(f.w, "\nCycles:")
+		if len(r.Cycles) == 0 {
+			fmt.Fprintln(f.w, "  none")
+		}
+		for i, cycle := range r.Cycles {
+			fmt.Fprintf(f.w, "  %s %v\n", p.paint(colorNumber, strconv.Itoa(i+1)+":"), cycle)
+		}
+	}
+
+	if r.GraphOnly && r.Criterion == "prime" {
+		_, err := fmt.Fprintln(f.w)
+		return err
+	}
+
+	title := criterionTitles[r.Criterion]
+	if r.EntryOnly {
+		title = "Entry Paths"
+	}
+	fmt.Fprintf(f.w, "\n%s:\n", title)
+	if r.Truncated && r.Criterion == "prime" {
+		fmt.Fprintln(f.w, "  (incomplete: path enumeration was truncated)")
+	}
+	for i, path := range r.Requirements {
+		fmt.Fprintf(f.w, "  %s %v", p.paint(colorNumber, strconv.Itoa(i+1)+":"), path)
+		if reason, ok := r.Infeasible[i]; ok {
+			fmt.Fprintf(f.w, " (infeasible: %s)", reason)
+		}
+		if r.Coverage != nil {
+			fmt.Fprintf(f.w, " (%s)", r.Coverage[i])
+		}
+		if r.Hotness != nil {
+			fmt.Fprintf(f.w, " (hotness %d)", r.Hotness[i])
+		}
+		fmt.Fprintln(f.w)
+		for j, n := range path {
+			blocks := r.NodeBlocks(n)
+			fmt.Fprintf(f.w, "       %s: %s %s", p.node(n),
+				p.paint(colorBlock, blocksName(blocks)), chainRange(r.Fset, blocks))
+			if j+1 < len(path) {
+				from, to := blocks[len(blocks)-1], r.NodeBlocks(path[j+1])[0]
+				if r.Reversed {
+					// The edge is taken from the next node to this one.
+					next := r.NodeBlocks(path[j+1])
+					from, to = next[len(next)-1], blocks[0]
+				}
+				if cond, ok := r.Branches.Condition(from, to); ok {
+					fmt.Fprintf(f.w, ", %s", cond.Format(r.Fset))
+				}
+			}
+			fmt.Fprintln(f.w)
+		}
+		if f.source {
+			printPathSource(f.w, r, path, p)
+		}
+	}
+
+	if r.Coverage != nil {
+		full, partial := coverageCounts(r.Coverage)
+		fmt.Fprintf(f.w, "  %d of %d covered, %d partially (%.0f%%)\n",
+			full, len(r.Coverage), partial, coveragePercent(r.Coverage))
+	}
+
+	if r.Tours != nil {
+		fmt.Fprintln(f.w, "\nTest Paths:")
+		for i, tour := range r.Tours {
+			num := p.paint(colorNumber, strconv.Itoa(i+1)+":")
+			if tour.Kind == primepath.Infeasible {
+				fmt.Fprintf(f.w, "  %s infeasible\n", num)
+			} else {
+				fmt.Fprintf(f.w, "  %s %v (%s)\n", num, tour.Path, tour.Kind)
+			}
+		}
+	}
+
+	if r.TestPaths != nil {
+		fmt.Fprintln(f.w, "\nMinimal Test Paths:")
+		for i, path := range r.TestPaths {
+			covered := primepath.CoveredBy(path, r.Requirements)
+			for j := range covered {
+				covered[j]++
+			}
+			fmt.Fprintf(f.w, "  %s %v covers %s\n", p.paint(colorNumber, strconv.Itoa(i+1)+":"), path, joinInts(covered))
+		}
+		fmt.Fprintf(f.w, "  %d test paths instead of %d, one per requirement\n",
+			len(r.TestPaths), len(r.Requirements))
+	}
+	_, err := fmt.Fprintln(f.w)
+	return err
+}
+
+func (f *textFormatter) Close() error {
+	return nil
+}
+
+// printPathSource prints the statements of the blocks along path in the
+// order they run, noting the outcome of each branch and marking blocks
+// the path already went through.
+func printPathSource(w io.Writer, r *funcResult, path []int, p *palette) {
+	fmt.Fprintln(w, "       source:")
+	seen := make(map[int]bool)
+	for j, n := range path {
+		again := seen[n]
+		seen[n] = true
+		blocks := r.NodeBlocks(n)
+
+		var cond primepath.Condition
+		ok := false
+		if j+1 < len(path) {
+			from, to := blocks[len(blocks)-1], r.NodeBlocks(path[j+1])[0]
+			if r.Reversed {
+				next := r.NodeBlocks(path[j+1])
+				from, to = next[len(next)-1], blocks[0]
+			}
+			cond, ok = r.Branches.Condition(from, to)
+		}
+
+		var last ast.Node
+		for _, block := range blocks {
+			if block.Index == 0 && r.Signature != "" {
+				fmt.Fprintf(w, "         %s\n", r.Signature)
+			}
+			for _, node := range block.Nodes {
+				if last != nil {
+					printSourceLine(w, r.Fset, last, again, "", p)
+				}
+				last = node
+			}
+		}
+		switch {
+		case ok && cond.Kind == primepath.BoolCondition && cond.Node == last:
+			printSourceLine(w, r.Fset, last, again, strconv.FormatBool(cond.Value), p)
+		case ok:
+			if last != nil {
+				printSourceLine(w, r.Fset, last, again, "", p)
+			}
+			fmt.Fprintf(w, "         %s\n", p.paint(colorEdge, "// "+cond.Format(r.Fset)))
+		case last != nil:
+			printSourceLine(w, r.Fset, last, again, "", p)
+		}
+	}
+}
+
+// printSourceLine prints node for printPathSource, followed by a comment
+// marking a repeated block and the outcome of its condition, if any.
+func printSourceLine(w io.Writer, fset *token.FileSet, node ast.Node, again bool, outcome string, p *palette) {
+	var notes []string
+	if again {
+		notes = append(notes, "again")
+	}
+	if outcome != "" {
+		notes = append(notes, outcome)
+	}
+	text := strings.ReplaceAll(nodeString(fset, node), "\n", "\n         ")
+	if len(notes) > 0 {
+		text += p.paint(colorEdge, "  // "+strings.Join(notes, "; "))
+	}
+	fmt.Fprintf(w, "         %s\n", text)
+}
+
+// printCFG dumps the blocks of g. With comments, it also shows the label
+// of labeled blocks and the comments attached to each statement; a
+// signature is shown first in block 0, the entry.
+func printCFG(w io.Writer, g *cfg.CFG, fset *token.FileSet, comments ast.CommentMap, signature string, p *palette) {
+	for _, block := range primepath.SortedBlocks(g) {
+		fmt.Fprintf(w, "  %s", p.paint(colorBlock, "Block "+strconv.Itoa(int(block.Index))))
+		if block.Live {
+			fmt.Fprint(w, " (live)")
+		}
+		fmt.Fprintln(w)
+
+		if label, ok := block.Stmt.(*ast.LabeledStmt); ok && comments != nil && block.Kind == cfg.KindLabel {
+			fmt.Fprintf(w, "    %s:\n", label.Label.Name)
+		}
+		if block.Index == 0 && signature != "" {
+			fmt.Fprintf(w, "      %s\n", signature)
+		}
+		for _, node := range block.Nodes {
+			for _, group := range comments[node] {
+				for _, c := range group.List {
+					fmt.Fprintf(w, "      %s\n", c.Text)
+				}
+			}
+			fmt.Fprintf(w, "      %s\n", nodeString(fset, node))
+		}
+
+		if len(block.Succs) > 0 {
+			fmt.Fprintf(w, "    %s ", p.paint(colorEdge, "->"))
+			for i, succ := range block.Succs {
+				if i > 0 {
+					fmt.Fprint(w, ", ")
+				}
+				fmt.Fprint(w, p.paint(colorBlock, "Block "+strconv.Itoa(int(succ.Index))))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func printGraphInfo(w io.Writer, graph [][]int, p *palette) {
+	fmt.Fprintln(w, "\nGraph Info:")
+
+	fmt.Fprintln(w, "Edges:")
+	edges := primepath.EdgeRequirements(graph)
+	if len(edges) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	kinds := edgeKinds(graph)
+	for _, e := range edges {
+		fmt.Fprintf(w, "  %s %s", p.node(e[0]), p.node(e[1]))
+		if kind := kinds[e]; kind != primepath.TreeEdge {
+			fmt.Fprintf(w, " (%s)", kind)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Initial nodes: %s\n", p.paint(colorInitial, joinInts(primepath.InitialNodes(graph))))
+	if final := primepath.FinalNodes(graph); len(final) > 0 {
+		fmt.Fprintf(w, "Final nodes: %s\n", p.paint(colorFinal, joinInts(final)))
+	} else {
+		fmt.Fprintf(w, "Final nodes: none, function has no exit (tours end at loop heads %s)\n",
+			joinInts(primepath.LoopHeads(graph)))
+	}
+}
+
+func joinInts(nodes []int) string {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func blockRange(fset *token.FileSet, block *cfg.Block) string {
+	return chainRange(fset, []*cfg.Block{block})
+}
+
+// chainRange describes the lines spanned by a chain of blocks, which
+// spliced deferred calls can take back up the function.
+func chainRange(fset *token.FileSet, blocks []*cfg.Block) string {
+	start, end := blocksLines(fset, blocks)
+	switch {
+	case start == 0:
+		return "(empty)"
+	case start == end:
+		return fmt.Sprintf("(line %d)", start)
+	}
+	return fmt.Sprintf("(lines %d-%d)", start, end)
+}
+
+// blocksLines returns the first and last source line spanned by the
+// statements of blocks, which need not be in source order, or zeros if none
+// has statements.
+func blocksLines(fset *token.FileSet, blocks []*cfg.Block) (start, end int) {
+	for _, block := range blocks {
+		if s, e := primepath.BlockLines(fset, block); s != 0 {
+			if start == 0 || s < start {
+				start = s
+			}
+			end = max(end, e)
+		}
+	}
+	return start, end
+}
+
+// blocksName names blocks "block 3" or, for a collapsed chain, "blocks
+// 3, 4, 5".
+func blocksName(blocks []*cfg.Block) string {
+	if len(blocks) == 1 {
+		return "block " + strconv.Itoa(int(blocks[0].Index))
+	}
+	indices := make([]int, len(blocks))
+	for i, block := range blocks {
+		indices[i] = int(block.Index)
+	}
+	return "blocks " + joinInts(indices)
+}
+
+func coverageCounts(coverage []primepath.Coverage) (full, partial int) {
+	for _, c := range coverage {
+		switch c {
+		case primepath.FullyCovered:
+			full++
+		case primepath.PartiallyCovered:
+			partial++
+		}
+	}
+	return full, partial
+}
+
+// coveragePercent is the share of requirements fully covered, 100 when
+// there are none.
+func coveragePercent(coverage []primepath.Coverage) float64 {
+	if len(coverage) == 0 {
+		return 100
+	}
+	full, _ := coverageCounts(coverage)
+	return 100 * float64(full) / float64(len(coverage))
+}